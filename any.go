@@ -0,0 +1,47 @@
+package deepequal
+
+import (
+	"math"
+	"reflect"
+)
+
+// anyValue is the type of Any.
+type anyValue struct{}
+
+func (anyValue) String() string {
+	return "deepequal.Any"
+}
+
+// Any matches any value, including nil, when it's found in the expected
+// (first) value at a position of an interface type, like an interface{}
+// struct field or a map[string]interface{} value. Typed positions can't
+// hold it, use Subset to skip unset fields of them.
+var Any interface{} = anyValue{}
+
+var anyType = reflect.TypeOf(anyValue{})
+
+// isAny tells if the interface value v holds Any.
+func isAny(v reflect.Value) bool {
+	return !v.IsNil() && v.Elem().Type() == anyType
+}
+
+// anyFloatBits is the bit pattern of AnyFloat, a quiet NaN with a payload
+// no arithmetic produces.
+const anyFloatBits = 0x7ff8deadbeef0001
+
+// AnyFloat matches any float64 value when it's found in the expected
+// (first) value, including NaN. It's a NaN told apart by its bits, so it
+// matches only with Compare functions, never with ==. Use AnyFloat32 for
+// float32 positions.
+var AnyFloat = math.Float64frombits(anyFloatBits)
+
+// AnyFloat32 matches any float32 value like AnyFloat.
+var AnyFloat32 = math.Float32frombits(0x7fdeadbf)
+
+// isAnyFloat tells if the float value v is AnyFloat (or AnyFloat32).
+func isAnyFloat(v reflect.Value) bool {
+	if v.Kind() == reflect.Float32 {
+		return math.Float32bits(float32(v.Float())) == math.Float32bits(AnyFloat32)
+	}
+	return math.Float64bits(v.Float()) == anyFloatBits
+}
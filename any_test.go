@@ -0,0 +1,133 @@
+package deepequal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompare_Any(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "top level",
+			a1:   Any,
+			a2:   testStruct{Name: "a"},
+			want: true,
+		},
+		{
+			name: "struct field",
+			a1:   testEnvelope{Payload: Any, Items: []testEnvelope{{Payload: 1}}},
+			a2:   testEnvelope{Payload: []int{1, 2}, Items: []testEnvelope{{Payload: 1}}},
+			want: true,
+		},
+		{
+			name: "matches nil",
+			a1:   testEnvelope{Payload: Any},
+			a2:   testEnvelope{},
+			want: true,
+		},
+		{
+			name: "nested",
+			a1:   map[string]interface{}{"id": Any, "tags": []interface{}{"a", Any}, "n": 1},
+			a2:   map[string]interface{}{"id": 42, "tags": []interface{}{"a", "b"}, "n": 1},
+			want: true,
+		},
+		{
+			name:       "nested differ",
+			a1:         map[string]interface{}{"id": Any, "tags": []interface{}{"a", Any}},
+			a2:         map[string]interface{}{"id": 42, "tags": []interface{}{"b", "c"}},
+			want:       false,
+			wantReason: "[tags] [0] scalar values differ",
+		},
+		{
+			name:       "expected side only",
+			a1:         []interface{}{1},
+			a2:         []interface{}{Any},
+			want:       false,
+			wantReason: "[0] values are of differing types: int vs deepequal.anyValue",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+type testReading struct {
+	Sensor string
+	Value  float64
+	Raw    []float32
+}
+
+func TestCompare_AnyFloat(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "top level",
+			a1:   AnyFloat,
+			a2:   1.5,
+			want: true,
+		},
+		{
+			name: "nested",
+			a1:   []testReading{{Sensor: "a", Value: AnyFloat, Raw: []float32{1, AnyFloat32}}},
+			a2:   []testReading{{Sensor: "a", Value: 20.5, Raw: []float32{1, 7}}},
+			want: true,
+		},
+		{
+			name: "matches NaN",
+			a1:   map[string]float64{"x": AnyFloat},
+			a2:   map[string]float64{"x": math.NaN()},
+			want: true,
+		},
+		{
+			name:       "other fields compared",
+			a1:         testReading{Sensor: "a", Value: AnyFloat},
+			a2:         testReading{Sensor: "b", Value: 1},
+			want:       false,
+			wantReason: "struct.Sensor scalar values differ",
+		},
+		{
+			name:       "only in expected",
+			a1:         testReading{Value: 1},
+			a2:         testReading{Value: AnyFloat},
+			want:       false,
+			wantReason: "struct.Value scalar values differ",
+		},
+		{
+			name:       "not a plain NaN",
+			a1:         testReading{Value: math.NaN()},
+			a2:         testReading{Value: 1},
+			want:       false,
+			wantReason: "struct.Value scalar values differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package deepequal
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// byNamePair tells if values of the different types t1 and t2 are compared
+// with StructsByName: both are structs, pointers or slices.
+func byNamePair(t1, t2 reflect.Type) bool {
+	if t1.Kind() != t2.Kind() {
+		return false
+	}
+	switch t1.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+// fieldIndex returns the index of the field of struct type t declared with
+// the name, or -1. Promoted fields aren't looked up.
+func fieldIndex(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// structsByName compares structs of different types by the fields with the
+// same names, fields declared only in one of them differ (with Subset only
+// the fields of the first one matter).
+func (c *comparer) structsByName(v1, v2 reflect.Value, depth int) (bool, string) {
+	t1, t2 := v1.Type(), v2.Type()
+	result := true
+	for i := 0; i < t1.NumField(); i++ {
+		field := t1.Field(i)
+		if c.ignored(field.Type) {
+			continue
+		}
+		name := field.Name
+		unexported := name[0] < 'A' || name[0] > 'Z'
+		if unexported && (c.opts.SkipUnexported || c.opts.PublicSurfaceOnly || c.opts.SkipUnexportedIn[t1]) {
+			continue
+		}
+		var equal bool
+		var reason string
+		j := fieldIndex(t2, name)
+		switch {
+		case j < 0:
+			equal, reason = c.elemDiff(fieldElem(name), v1.Field(i), reflect.Value{}, "field missing in second struct")
+		case c.opts.RequireFieldOrder && i != j:
+			equal, reason = c.elemDiff(fieldElem(name), v1.Field(i), v2.Field(j), fmt.Sprintf("field order differs: %d vs %d", i, j))
+		case unexported:
+			equal, reason = c.elemDiff(fieldElem(name), v1.Field(i), v2.Field(j), "unexported")
+		case c.opts.Subset && c.isEmpty(v1.Field(i)):
+			continue
+		default:
+			equal, reason = c.descend(fieldElem(name), v1.Field(i), v2.Field(j), depth, c.deepValueEqual)
+		}
+		if !equal {
+			if !c.all {
+				return false, reason
+			}
+			result = false
+		}
+	}
+	for j := 0; j < t2.NumField(); j++ {
+		field := t2.Field(j)
+		name := field.Name
+		if c.opts.Subset || c.ignored(field.Type) || fieldIndex(t1, name) >= 0 {
+			continue
+		}
+		if (name[0] < 'A' || name[0] > 'Z') && (c.opts.SkipUnexported || c.opts.PublicSurfaceOnly || c.opts.SkipUnexportedIn[t2]) {
+			continue
+		}
+		if equal, reason := c.elemDiff(fieldElem(name), reflect.Value{}, v2.Field(j), "field missing in first struct"); !equal {
+			if !c.all {
+				return false, reason
+			}
+			result = false
+		}
+	}
+	return result, ""
+}
+
+// pointerValuePair tells if only one of the different types t1 and t2 is a
+// pointer type, pointing to the other type or one compared with it anyway
+// (like a struct with StructsByName), for LenientPointers.
+func (c *comparer) pointerValuePair(t1, t2 reflect.Type) bool {
+	if t2.Kind() == reflect.Ptr {
+		t1, t2 = t2, t1
+	}
+	if t1.Kind() != reflect.Ptr || t2.Kind() == reflect.Ptr {
+		return false
+	}
+	elem := t1.Elem()
+	return elem == t2 || c.interchangeable(elem, t2)
+}
+
+// lenientPointer compares a pointer with a value of another type by the
+// value it points to, a nil pointer is equal to any value of that type.
+func (c *comparer) lenientPointer(v1, v2 reflect.Value, depth int) (bool, string) {
+	if v1.Kind() == reflect.Ptr {
+		if v1.IsNil() {
+			return true, ""
+		}
+		return c.deepValueEqual(v1.Elem(), v2, depth+1)
+	}
+	if v2.IsNil() {
+		return true, ""
+	}
+	return c.deepValueEqual(v1, v2.Elem(), depth+1)
+}
+
+// CompareByName compares structs of different types by the values of
+// their fields with the same names, at any depth (also through pointers
+// and slices), like Compare with Options.StructsByName.
+func CompareByName(a1, a2 interface{}) (bool, string) {
+	return CompareWithOptions(a1, a2, Options{StructsByName: true})
+}
+
+// CompareLenientPointers compares structs of different types by name like
+// CompareByName, with pointer fields matching value fields by the values
+// they point to, where nil pointers are absent fields which match any
+// value, like the optional fields of a request and those of a model.
+func CompareLenientPointers(a1, a2 interface{}) (bool, string) {
+	return CompareWithOptions(a1, a2, Options{StructsByName: true, LenientPointers: true})
+}
@@ -0,0 +1,259 @@
+package deepequal
+
+import "testing"
+
+type testUserV1 struct {
+	ID    int
+	Name  string
+	Tags  []string
+	Owner *testUserV1
+}
+
+type testUserV2 struct {
+	ID    int
+	Name  string
+	Tags  []string
+	Owner *testUserV2
+}
+
+type testUserReordered struct {
+	Name string
+	ID   int
+}
+
+type testUserShort struct {
+	ID   int
+	Name string
+}
+
+type testUserExtra struct {
+	ID    int
+	Name  string
+	Email string
+}
+
+func TestCompareByName(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal",
+			a1:   testUserV1{ID: 1, Name: "a", Tags: []string{"x"}, Owner: &testUserV1{ID: 2}},
+			a2:   testUserV2{ID: 1, Name: "a", Tags: []string{"x"}, Owner: &testUserV2{ID: 2}},
+			want: true,
+		},
+		{
+			name:       "nested differ",
+			a1:         &testUserV1{ID: 1, Owner: &testUserV1{ID: 2}},
+			a2:         &testUserV2{ID: 1, Owner: &testUserV2{ID: 3}},
+			want:       false,
+			wantReason: "struct.Owner struct.ID scalar values differ",
+		},
+		{
+			name: "slices",
+			a1:   []testUserShort{{ID: 1}, {ID: 2}},
+			a2:   []testUserReordered{{ID: 1}, {ID: 2}},
+			want: true,
+		},
+		{
+			name:       "missing in second",
+			a1:         testUserExtra{ID: 1},
+			a2:         testUserShort{ID: 1},
+			want:       false,
+			wantReason: "struct.Email field missing in second struct",
+		},
+		{
+			name:       "missing in first",
+			a1:         testUserShort{ID: 1},
+			a2:         testUserExtra{ID: 1},
+			want:       false,
+			wantReason: "struct.Email field missing in first struct",
+		},
+		{
+			name:       "field types differ",
+			a1:         testUserShort{ID: 1},
+			a2:         struct{ ID int64 }{ID: 1},
+			want:       false,
+			wantReason: "struct.ID values are of differing types: int vs int64",
+		},
+		{
+			name:       "other types",
+			a1:         testUserShort{ID: 1},
+			a2:         1,
+			want:       false,
+			wantReason: "values are of different types: deepequal.testUserShort vs int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareByName(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("CompareByName() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareByName() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareWithOptions_RequireFieldOrder(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "same order",
+			a1:   testUserShort{ID: 1, Name: "a"},
+			a2:   testUserExtra{ID: 1, Name: "a"},
+			opts: Options{StructsByName: true, RequireFieldOrder: true, Subset: true},
+			want: true,
+		},
+		{
+			name:       "reordered",
+			a1:         testUserShort{ID: 1, Name: "a"},
+			a2:         testUserReordered{ID: 1, Name: "a"},
+			opts:       Options{StructsByName: true, RequireFieldOrder: true},
+			want:       false,
+			wantReason: "struct.ID field order differs: 0 vs 1",
+		},
+		{
+			name: "reordered allowed",
+			a1:   testUserShort{ID: 1, Name: "a"},
+			a2:   testUserReordered{ID: 1, Name: "a"},
+			opts: Options{StructsByName: true},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         testUserShort{ID: 1, Name: "a"},
+			a2:         testUserReordered{ID: 1, Name: "a"},
+			want:       false,
+			wantReason: "values are of different types: deepequal.testUserShort vs deepequal.testUserReordered",
+		},
+	})
+}
+
+func TestCompareTo_RequireFieldOrder(t *testing.T) {
+	var got []string
+	compareAll(testUserShort{ID: 1, Name: "a"}, testUserReordered{ID: 2, Name: "a"},
+		Options{StructsByName: true, RequireFieldOrder: true}, func(d Difference) {
+			got = append(got, d.String())
+		})
+	want := []string{"struct.ID field order differs: 0 vs 1", "struct.Name field order differs: 1 vs 0"}
+	if ok, reason := Compare(got, want); !ok {
+		t.Errorf("differences = %q, want %q: %s", got, want, reason)
+	}
+}
+
+type testUpdateRequest struct {
+	Name    *string
+	Age     *int
+	Address *testAddressRequest
+}
+
+type testAddressRequest struct {
+	City *string
+}
+
+type testUserModel struct {
+	Name    string
+	Age     int
+	Address testAddressModel
+}
+
+type testAddressModel struct {
+	City string
+}
+
+func TestCompareLenientPointers(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	model := testUserModel{Name: "a", Age: 30, Address: testAddressModel{City: "x"}}
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "all set",
+			a1:   testUpdateRequest{Name: str("a"), Age: num(30), Address: &testAddressRequest{City: str("x")}},
+			a2:   testUserModel{Name: "a", Age: 30, Address: testAddressModel{City: "x"}},
+			want: true,
+		},
+		{
+			name:       "nested differ",
+			a1:         testUpdateRequest{Address: &testAddressRequest{City: str("y")}},
+			a2:         model,
+			want:       false,
+			wantReason: "struct.Address struct.City scalar values differ",
+		},
+		{
+			name: "nil pointers absent",
+			a1:   testUpdateRequest{Name: str("a")},
+			a2:   model,
+			want: true,
+		},
+		{
+			name:       "value differs",
+			a1:         testUpdateRequest{Age: num(31)},
+			a2:         model,
+			want:       false,
+			wantReason: "struct.Age scalar values differ",
+		},
+		{
+			name: "pointer on the second side",
+			a1:   model,
+			a2:   &testUpdateRequest{Name: str("a")},
+			want: true,
+		},
+		{
+			name:       "nil pointer to another type",
+			a1:         (*int)(nil),
+			a2:         "x",
+			want:       false,
+			wantReason: "values are of different types: *int vs string",
+		},
+		{
+			name:       "nil pointer to another type in interfaces",
+			a1:         []interface{}{(*int)(nil)},
+			a2:         []interface{}{"x"},
+			want:       false,
+			wantReason: "[0] values are of differing types: *int vs string",
+		},
+		{
+			name:       "pointer to another type",
+			a1:         map[string]interface{}{"n": num(1)},
+			a2:         map[string]interface{}{"n": int64(1)},
+			want:       false,
+			wantReason: "[n] values are of differing types: *int vs int64",
+		},
+		{
+			name: "nil pointer in interfaces",
+			a1:   []interface{}{(*int)(nil)},
+			a2:   []interface{}{5},
+			want: true,
+		},
+		{
+			name:       "different types",
+			a1:         testUpdateRequest{Name: str("a")},
+			a2:         testUserV1{Name: "a"},
+			want:       false,
+			wantReason: "struct.Age field missing in second struct",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareLenientPointers(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("CompareLenientPointers() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareLenientPointers() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package deepequal
+
+import "reflect"
+
+// CanCompare tells if values of type t can be compared by Compare without
+// hitting an unexported struct field or a function, which make Compare
+// fail. The reason locates the first such field like Compare reasons do,
+// with '[]' standing for any slice, array or map element. Interfaces are
+// assumed to hold comparable values.
+func CanCompare(t reflect.Type) (bool, string) {
+	return canCompare(t, make(map[reflect.Type]bool))
+}
+
+func canCompare(t reflect.Type, seen map[reflect.Type]bool) (bool, string) {
+	if seen[t] {
+		// recursive type, already checked or in progress
+		return true, ""
+	}
+	seen[t] = true
+	if _, ok := typeComparers[t]; ok || atomicComparer(t) != nil {
+		return true, ""
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		if ok, reason := canCompare(t.Elem(), seen); !ok {
+			return false, "[] " + reason
+		}
+	case reflect.Ptr:
+		return canCompare(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Name[0] < 'A' || f.Name[0] > 'Z' {
+				return false, "struct." + f.Name + " unexported"
+			}
+			if ok, reason := canCompare(f.Type, seen); !ok {
+				return false, "struct." + f.Name + " " + reason
+			}
+		}
+	case reflect.Func:
+		return false, "non-nil functions never compare equal"
+	}
+	return true, ""
+}
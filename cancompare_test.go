@@ -0,0 +1,94 @@
+package deepequal
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCallbacks struct {
+	Name  string
+	Hooks []testHandlers
+}
+
+type testTree struct {
+	Value    int
+	Children []*testTree
+	Parent   *testTree
+}
+
+type testWithBuffer struct {
+	Buf bytes.Buffer
+	Loc *time.Location
+}
+
+func TestCanCompare(t *testing.T) {
+	tests := []struct {
+		name       string
+		typ        reflect.Type
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "int",
+			typ:  reflect.TypeOf(1),
+			want: true,
+		},
+		{
+			name: "struct",
+			typ:  reflect.TypeOf(testStruct{}),
+			want: true,
+		},
+		{
+			name: "recursive",
+			typ:  reflect.TypeOf(&testTree{}),
+			want: true,
+		},
+		{
+			name: "special types",
+			typ:  reflect.TypeOf(testWithBuffer{}),
+			want: true,
+		},
+		{
+			name: "interface",
+			typ:  reflect.TypeOf(testInterfaces{}),
+			want: true,
+		},
+		{
+			name:       "unexported",
+			typ:        reflect.TypeOf(testStructS{}),
+			want:       false,
+			wantReason: "struct._name unexported",
+		},
+		{
+			name:       "nested unexported",
+			typ:        reflect.TypeOf(map[string][]*testStructS{}),
+			want:       false,
+			wantReason: "[] [] struct._name unexported",
+		},
+		{
+			name:       "func field",
+			typ:        reflect.TypeOf(testCallbacks{}),
+			want:       false,
+			wantReason: "struct.Hooks [] struct.Handler non-nil functions never compare equal",
+		},
+		{
+			name:       "func",
+			typ:        reflect.TypeOf(func() {}),
+			want:       false,
+			wantReason: "non-nil functions never compare equal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CanCompare(tt.typ)
+			if got != tt.want {
+				t.Errorf("CanCompare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CanCompare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package deepequal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorReset = "\x1b[0m"
+)
+
+// stdoutIsTerminal tells if the standard output is a terminal, where
+// CompareColor colors the differences.
+var stdoutIsTerminal = func() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// CompareColor compares a1 and a2 like Compare and renders every
+// difference found: its reason, then the expected (first) value in red
+// and the actual (second) one in green. Colors are used only when the
+// standard output is a terminal. It's empty for equal values.
+func CompareColor(a1, a2 interface{}) (bool, string) {
+	return CompareColorWithOptions(a1, a2, Options{})
+}
+
+// CompareColorWithOptions is CompareColor with the comparison adjusted by
+// opts, Options.ForceColor uses colors even if the standard output isn't
+// a terminal.
+func CompareColorWithOptions(a1, a2 interface{}, opts Options) (bool, string) {
+	color := opts.ForceColor || stdoutIsTerminal()
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + colorReset
+	}
+	var b strings.Builder
+	equal := compareAll(a1, a2, opts, func(d Difference) {
+		b.WriteString(d.String())
+		b.WriteByte('\n')
+		fmt.Fprintf(&b, "\t%s\n", paint(colorRed, fmt.Sprintf("- %+v", d.A)))
+		fmt.Fprintf(&b, "\t%s\n", paint(colorGreen, fmt.Sprintf("+ %+v", d.B)))
+	})
+	return equal, b.String()
+}
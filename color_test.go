@@ -0,0 +1,69 @@
+package deepequal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareColor(t *testing.T) {
+	a1 := testStruct{Name: "a", S: []int{1, 2}}
+	a2 := testStruct{Name: "b", S: []int{1, 3}}
+	tests := []struct {
+		name     string
+		terminal bool
+		opts     Options
+		want     string
+	}{
+		{
+			name: "plain",
+			want: "struct.Name scalar values differ\n" +
+				"\t- a\n" +
+				"\t+ b\n" +
+				"struct.S [1] scalar values differ\n" +
+				"\t- 2\n" +
+				"\t+ 3\n",
+		},
+		{
+			name: "forced",
+			opts: Options{ForceColor: true},
+			want: "struct.Name scalar values differ\n" +
+				"\t\x1b[31m- a\x1b[0m\n" +
+				"\t\x1b[32m+ b\x1b[0m\n" +
+				"struct.S [1] scalar values differ\n" +
+				"\t\x1b[31m- 2\x1b[0m\n" +
+				"\t\x1b[32m+ 3\x1b[0m\n",
+		},
+		{
+			name:     "terminal",
+			terminal: true,
+			want: "struct.Name scalar values differ\n" +
+				"\t\x1b[31m- a\x1b[0m\n" +
+				"\t\x1b[32m+ b\x1b[0m\n" +
+				"struct.S [1] scalar values differ\n" +
+				"\t\x1b[31m- 2\x1b[0m\n" +
+				"\t\x1b[32m+ 3\x1b[0m\n",
+		},
+	}
+	old := stdoutIsTerminal
+	defer func() { stdoutIsTerminal = old }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdoutIsTerminal = func() bool { return tt.terminal }
+			equal, got := CompareColorWithOptions(a1, a2, tt.opts)
+			if equal {
+				t.Errorf("CompareColorWithOptions() = true, want false")
+			}
+			if got != tt.want {
+				t.Errorf("CompareColorWithOptions() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if colored := strings.Contains(got, "\x1b["); colored != (tt.terminal || tt.opts.ForceColor) {
+				t.Errorf("CompareColorWithOptions() colored = %v", colored)
+			}
+		})
+	}
+
+	stdoutIsTerminal = func() bool { return false }
+	if equal, got := CompareColor(a1, a1); !equal || got != "" {
+		t.Errorf("CompareColor() of equal values = %v, %q", equal, got)
+	}
+}
@@ -0,0 +1,105 @@
+package deepequal
+
+import "reflect"
+
+// CompareCopy checks a clone function: it compares original and copy like
+// Compare and returns the paths (formatted like in reasons) where copy
+// shares memory with original instead of holding its own copy: the same
+// pointer or map, or slices with overlapping backing arrays. An empty path
+// means the compared values themselves. Aliased values aren't looked into.
+func CompareCopy(original, copy interface{}) (equalContent bool, aliasPaths []string) {
+	equalContent, _ = Compare(original, copy)
+	f := aliasFinder{visited: make(map[visit]bool)}
+	f.walk(reflect.ValueOf(original), reflect.ValueOf(copy))
+	return equalContent, f.paths
+}
+
+// aliasFinder walks two values side by side looking for shared memory.
+type aliasFinder struct {
+	visited map[visit]bool
+	path    []PathElem
+	paths   []string
+}
+
+// seen tells if the references p1 and p2 of type t were already walked,
+// which stops on recursive types.
+func (f *aliasFinder) seen(p1, p2 uintptr, t reflect.Type) bool {
+	v := visit{p1, p2, t}
+	if f.visited[v] {
+		return true
+	}
+	f.visited[v] = true
+	return false
+}
+
+func (f *aliasFinder) alias() {
+	f.paths = append(f.paths, formatPath(f.path))
+}
+
+func (f *aliasFinder) walkElem(elem PathElem, v1, v2 reflect.Value) {
+	f.path = append(f.path, elem)
+	f.walk(v1, v2)
+	f.path = f.path[:len(f.path)-1]
+}
+
+func (f *aliasFinder) walk(v1, v2 reflect.Value) {
+	if !v1.IsValid() || !v2.IsValid() || v1.Type() != v2.Type() {
+		return
+	}
+	switch v1.Kind() {
+	case reflect.Ptr:
+		if v1.IsNil() || v2.IsNil() {
+			return
+		}
+		if v1.Pointer() == v2.Pointer() {
+			f.alias()
+			return
+		}
+		if f.seen(v1.Pointer(), v2.Pointer(), v1.Type()) {
+			return
+		}
+		f.walk(v1.Elem(), v2.Elem())
+	case reflect.Map:
+		if v1.IsNil() || v2.IsNil() {
+			return
+		}
+		if v1.Pointer() == v2.Pointer() {
+			f.alias()
+			return
+		}
+		if f.seen(v1.Pointer(), v2.Pointer(), v1.Type()) {
+			return
+		}
+		for _, k := range v1.MapKeys() {
+			f.walkElem(keyElem(k), v1.MapIndex(k), v2.MapIndex(k))
+		}
+	case reflect.Slice:
+		if v1.Cap() == 0 || v2.Cap() == 0 {
+			return
+		}
+		if _, _, _, ok := backingOverlap(v1, v2); ok {
+			f.alias()
+			return
+		}
+		if f.seen(v1.Pointer(), v2.Pointer(), v1.Type()) {
+			return
+		}
+		n := v1.Len()
+		if v2.Len() < n {
+			n = v2.Len()
+		}
+		for i := 0; i < n; i++ {
+			f.walkElem(indexElem(i), v1.Index(i), v2.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v1.Len(); i++ {
+			f.walkElem(indexElem(i), v1.Index(i), v2.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v1.NumField(); i++ {
+			f.walkElem(fieldElem(v1.Type().Field(i).Name), v1.Field(i), v2.Field(i))
+		}
+	case reflect.Interface:
+		f.walk(v1.Elem(), v2.Elem())
+	}
+}
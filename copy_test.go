@@ -0,0 +1,110 @@
+package deepequal
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testDoc struct {
+	Title  string
+	Tags   []string
+	Meta   map[string]string
+	Author *testAuthor
+}
+
+type testAuthor struct {
+	Name string
+}
+
+func (d *testDoc) deepCopy() *testDoc {
+	c := &testDoc{Title: d.Title, Tags: append([]string(nil), d.Tags...), Meta: make(map[string]string)}
+	for k, v := range d.Meta {
+		c.Meta[k] = v
+	}
+	if d.Author != nil {
+		a := *d.Author
+		c.Author = &a
+	}
+	return c
+}
+
+func (d *testDoc) shallowCopy() *testDoc {
+	c := *d
+	return &c
+}
+
+func TestCompareCopy(t *testing.T) {
+	doc := &testDoc{
+		Title:  "a",
+		Tags:   []string{"x", "y"},
+		Meta:   map[string]string{"k": "v"},
+		Author: &testAuthor{Name: "n"},
+	}
+	tests := []struct {
+		name      string
+		copy      interface{}
+		wantEqual bool
+		wantPaths []string
+	}{
+		{
+			name:      "deep copy",
+			copy:      doc.deepCopy(),
+			wantEqual: true,
+		},
+		{
+			name:      "shallow copy",
+			copy:      doc.shallowCopy(),
+			wantEqual: true,
+			wantPaths: []string{"struct.Tags", "struct.Meta", "struct.Author"},
+		},
+		{
+			name:      "same pointer",
+			copy:      doc,
+			wantEqual: true,
+			wantPaths: []string{""},
+		},
+		{
+			name: "subslice",
+			copy: &testDoc{
+				Title:  "a",
+				Tags:   doc.Tags[1:],
+				Meta:   map[string]string{"k": "v"},
+				Author: &testAuthor{Name: "n"},
+			},
+			wantEqual: false,
+			wantPaths: []string{"struct.Tags"},
+		},
+		{
+			name: "changed copy",
+			copy: &testDoc{
+				Title:  "b",
+				Tags:   []string{"x", "y"},
+				Meta:   doc.Meta,
+				Author: &testAuthor{Name: "n"},
+			},
+			wantEqual: false,
+			wantPaths: []string{"struct.Meta"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotEqual, gotPaths := CompareCopy(doc, tt.copy)
+			if gotEqual != tt.wantEqual {
+				t.Errorf("CompareCopy() equalContent = %v, want %v", gotEqual, tt.wantEqual)
+			}
+			if !reflect.DeepEqual(gotPaths, tt.wantPaths) {
+				t.Errorf("CompareCopy() aliasPaths = %q, want %q", gotPaths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestCompareCopy_Recursive(t *testing.T) {
+	n1 := &testNode{}
+	n1.Next = n1
+	n2 := &testNode{}
+	n2.Next = n2
+	if _, paths := CompareCopy(n1, n2); len(paths) != 0 {
+		t.Errorf("CompareCopy() aliasPaths = %q, want none", paths)
+	}
+}
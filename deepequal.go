@@ -6,8 +6,10 @@ package deepequal
 
 import (
 	"fmt"
-	"math"
 	"reflect"
+	"strings"
+	"sync/atomic"
+	"unsafe"
 )
 
 // During deepValueEqual, must keep track of checks that are
@@ -20,17 +22,136 @@ type visit struct {
 	typ reflect.Type
 }
 
-// Tests for deep equality using reflected types. The map argument tracks
-// comparisons that have already been seen, which allows short circuiting on
-// recursive types.
-func deepValueEqual(v1, v2 reflect.Value, visited map[visit]bool, depth int, skipUnexported bool) (bool, string) {
+// comparer holds the state of a single comparison.
+type comparer struct {
+	opts *Options
+	// visited tracks comparisons that have already been seen, which allows
+	// short circuiting on recursive types.
+	visited map[visit]bool
+
+	// path is the current path from the compared values.
+	path []PathElem
+	// all carries on after a difference to find all of them.
+	all bool
+	// node is the current node of the tree built by CompareTree.
+	node *DiffNode
+	// report gets every difference found.
+	report func(d Difference)
+	// trackFirst keeps the first difference found in first.
+	trackFirst bool
+	first      *Difference
+	// inMethods is set while comparing method results with
+	// PublicSurfaceOnly, methods of them are not called.
+	inMethods bool
+	// schema compares only the map keys set in the first value, for
+	// CompareSchema.
+	schema bool
+	// stop is set (atomically) to abandon a comparison which timed out.
+	stop *int32
+	// unorderedSlice compares the next slice ignoring the order of its
+	// elements, for map values with UnorderedMapValueSlices.
+	unorderedSlice bool
+}
+
+func newComparer(opts *Options) *comparer {
+	return &comparer{opts: opts, visited: make(map[visit]bool)}
+}
+
+// probe returns a comparer for trial comparisons, which only tell if the
+// values are equal, with its own visited set.
+func (c *comparer) probe() *comparer {
+	p := newComparer(c.opts)
+	p.inMethods = c.inMethods
+	p.schema = c.schema
+	p.stop = c.stop
+	return p
+}
+
+// Tests for deep equality using reflected types.
+func (c *comparer) deepValueEqual(v1, v2 reflect.Value, depth int) (bool, string) {
+	if c.stop != nil && atomic.LoadInt32(c.stop) != 0 {
+		return false, timedOut
+	}
 	if !v1.IsValid() || !v2.IsValid() {
-		return v1.IsValid() == v2.IsValid(), "invalid values are not equal"
+		return bothOrNone(v1.IsValid(), v2.IsValid(), "invalid values are not equal")
 	}
 	if v1.Type() != v2.Type() {
-		return false, "values are of differing types"
+		if c.opts.StringBytesInterchangeable && stringBytesPair(v1.Type(), v2.Type()) {
+			return compareStringBytes(v1, v2)
+		}
+		if c.opts.SignedUnsignedEqual && signedUnsignedPair(v1.Type(), v2.Type()) {
+			return compareSignedUnsigned(v1, v2)
+		}
+		if c.opts.LenientPointers && c.pointerValuePair(v1.Type(), v2.Type()) {
+			return c.lenientPointer(v1, v2, depth)
+		}
+		if c.opts.StructsByName && byNamePair(v1.Type(), v2.Type()) {
+			// the hooks need values of the same type
+			return c.kindEqual(v1, v2, depth)
+		}
+		return false, fmt.Sprintf("values are of differing types: %v vs %v", v1.Type(), v2.Type())
 	}
 
+	if c.ignored(v1.Type()) {
+		return true, ""
+	}
+
+	if handled, equal, reason := c.hookEqual(v1, v2, depth); handled {
+		if c.opts.ShapeOnly {
+			// the hooks compare data, the types match already
+			return true, ""
+		}
+		return equal, reason
+	}
+	if c.opts.UseCanonicalMethod {
+		if n1, n2, ok := canonicalMethod(v1, v2); ok {
+			// compare by kind, Canonical of the result isn't called again
+			return c.kindEqual(n1, n2, depth)
+		}
+	}
+
+	return c.kindEqual(v1, v2, depth)
+}
+
+// hookEqual compares v1 and v2 of the same type with the type comparers
+// and the enabled value hooks, like UseDiffMethod. handled is false if
+// none of them applies.
+func (c *comparer) hookEqual(v1, v2 reflect.Value, depth int) (handled, equal bool, reason string) {
+	if handled, equal, reason = c.compareType(v1, v2, depth); handled {
+		return
+	}
+
+	if c.opts.ProtoEqual != nil && isProtoMessage(v1) && v2.CanInterface() {
+		if c.opts.ProtoEqual(v1.Interface(), v2.Interface()) {
+			return true, true, ""
+		}
+		return true, false, "proto messages differ"
+	}
+	if c.opts.UseDiffMethod {
+		if handled, equal, reason = diffMethod(v1, v2); handled {
+			return
+		}
+	}
+	if c.opts.StringerTypes[v1.Type()] {
+		if handled, equal, reason = stringMethod(v1, v2); handled {
+			return
+		}
+	}
+	if c.opts.UseBinaryMarshaler {
+		if handled, equal, reason = binaryMarshaler(v1, v2); handled {
+			return
+		}
+	}
+	if c.opts.CompareErrorChain && v1.Kind() != reflect.Interface {
+		if handled, equal, reason = compareErrors(v1, v2); handled {
+			return
+		}
+	}
+	return false, false, ""
+}
+
+// kindEqual tests values of the same type for deep equality by their kind.
+func (c *comparer) kindEqual(v1, v2 reflect.Value, depth int) (equal bool, reason string) {
 	// if depth > 10 { panic("deepValueEqual") }	// for debugging
 	hard := func(k reflect.Kind) bool {
 		switch k {
@@ -56,71 +177,150 @@ func deepValueEqual(v1, v2 reflect.Value, visited map[visit]bool, depth int, ski
 		// ... or already seen
 		typ := v1.Type()
 		v := visit{addr1, addr2, typ}
-		if visited[v] {
+		if c.visited[v] {
 			return true, ""
 		}
 
 		// Remember for later.
-		visited[v] = true
+		c.visited[v] = true
+		if c.all {
+			// carrying on after a difference, a pair which differs must
+			// be compared (and reported) again wherever it's found
+			defer func() {
+				if !equal {
+					delete(c.visited, v)
+				}
+			}()
+		}
 	}
 
 	switch v1.Kind() {
 	case reflect.Float32, reflect.Float64:
-		fV1 := v1.Float()
-		fV2 := v2.Float()
-		if math.IsNaN(fV1) && math.IsNaN(fV2) {
+		if c.opts.ShapeOnly {
 			return true, ""
 		}
-		if fV1 == fV2 {
-			return true, ""
-		}
-		return false, "scalar values differ"
+		return c.floatEqual(v1, v2)
 	case reflect.Array:
-		for i := 0; i < v1.Len(); i++ {
-			if equal, reason := deepValueEqual(v1.Index(i), v2.Index(i), visited, depth+1, skipUnexported); !equal {
-				return false, reason
-			}
+		if c.opts.UnorderedEverywhere {
+			return c.unorderedEqual(v1, v2, depth)
 		}
-		return true, ""
+		return c.elemsEqual(v1, v2, depth)
 	case reflect.Slice:
-		if v1.IsNil() != v2.IsNil() {
+		unordered := c.unorderedSlice || c.opts.UnorderedSlicesDeep || c.opts.UnorderedEverywhere
+		// only the slice itself, not the slices in it
+		c.unorderedSlice = false
+		if !c.ignoreNil() && v1.IsNil() != v2.IsNil() {
 			return false, "one slice is nil, the other is not"
 		}
+		if limit := c.opts.MaxSliceElements; limit > 0 {
+			v1, v2 = truncateSlice(v1, limit), truncateSlice(v2, limit)
+		}
+		if c.opts.TrimNilSliceEnds {
+			v1, v2 = trimZeroEnds(v1), trimZeroEnds(v2)
+		}
+		if c.opts.RuneSlicesAsStrings && v1.Type() == runesType {
+			return compareRunes(v1, v2)
+		}
+		if c.opts.Base64Bytes && isBytes(v1.Type()) {
+			return compareBase64(v1, v2)
+		}
+		if c.opts.SliceKeyField != "" {
+			if handled, equal, reason := c.sliceByKey(v1, v2, depth); handled {
+				return equal, reason
+			}
+		}
+		if tolerance := c.opts.SliceLengthTolerance; tolerance > 0 && v1.Len() != v2.Len() {
+			if n := v1.Len() - v2.Len(); n <= tolerance && -n <= tolerance {
+				v1, v2 = truncateSlice(v1, v2.Len()), truncateSlice(v2, v1.Len())
+			}
+		}
 		if v1.Len() != v2.Len() {
+			if c.opts.SliceDivergence {
+				return false, c.sliceDivergence(v1, v2, depth)
+			}
 			return false, "slices have different lengths"
 		}
 		if v1.Pointer() == v2.Pointer() {
 			return true, ""
 		}
-		for i := 0; i < v1.Len(); i++ {
-			if equal, reason := deepValueEqual(v1.Index(i), v2.Index(i), visited, depth+1, skipUnexported); !equal {
-				return false, fmt.Sprintf("[%d] %s", i, reason)
-			}
+		if unordered {
+			return c.unorderedEqual(v1, v2, depth)
 		}
-		return true, ""
+		return c.elemsEqual(v1, v2, depth)
 	case reflect.Interface:
+		if isAny(v1) {
+			return true, ""
+		}
 		if v1.IsNil() || v2.IsNil() {
-			return v1.IsNil() == v2.IsNil(), "both interfaces must be nil"
+			if c.opts.NilInterfaceEqualsEmptySlice && (isEmptyContainer(v1.Elem()) || isEmptyContainer(v2.Elem())) {
+				return true, ""
+			}
+			return bothOrNone(v1.IsNil(), v2.IsNil(), "both interfaces must be nil")
+		}
+		if c.opts.LooseInterfaceNumerics {
+			if handled, equal, reason := looseNumbers(v1.Elem(), v2.Elem()); handled {
+				return equal, reason
+			}
+		}
+		if c.opts.CompareErrorChain {
+			// the chains may hold errors of different types
+			if handled, equal, reason := compareErrors(v1.Elem(), v2.Elem()); handled {
+				return equal, reason
+			}
 		}
-		return deepValueEqual(v1.Elem(), v2.Elem(), visited, depth+1, skipUnexported)
+		if c.opts.CompareByInterface != nil {
+			if handled, equal, reason := c.compareByInterface(c.opts.CompareByInterface, v1.Elem(), v2.Elem(), depth); handled {
+				return equal, reason
+			}
+		}
+		return c.deepValueEqual(v1.Elem(), v2.Elem(), depth+1)
 	case reflect.Ptr:
-		return deepValueEqual(v1.Elem(), v2.Elem(), visited, depth+1, skipUnexported)
-	case reflect.Struct:
-		for i, n := 0, v1.NumField(); i < n; i++ {
-			name := v1.Type().Field(i).Name
-			if name[0] < 'A' || name[0] > 'Z' {
-				if skipUnexported {
-					return true, ""
-				}
-				return false, "struct." + name + " unexported"
+		if v1.IsNil() || v2.IsNil() {
+			if c.opts.NormalizeNils && nilOrEmptyPointer(v1) && nilOrEmptyPointer(v2) {
+				return true, ""
 			}
-			if equal, reason := deepValueEqual(v1.Field(i), v2.Field(i), visited, depth+1, skipUnexported); !equal {
-				return false, "struct." + name + " " + reason
+			return bothOrNone(v1.IsNil(), v2.IsNil(), "one pointer is nil, the other is not")
+		}
+		if c.opts.PointerIdentity {
+			if v1.Pointer() != v2.Pointer() {
+				return false, "pointers refer to different objects"
 			}
+			return true, ""
 		}
-		return true, ""
+		if t := v1.Type().Elem(); c.opts.RequirePointerIdentity[t] && v1.Pointer() != v2.Pointer() {
+			return false, fmt.Sprintf("expected same %v instance", t)
+		}
+		if depth == 0 && c.opts.Parallel > 1 {
+			// the struct pointed to by the compared values is still
+			// compared in parallel
+			return c.deepValueEqual(v1.Elem(), v2.Elem(), 0)
+		}
+		return c.deepValueEqual(v1.Elem(), v2.Elem(), depth+1)
+	case reflect.Struct:
+		if v1.Type() != v2.Type() {
+			return c.structsByName(v1, v2, depth)
+		}
+		n := v1.NumField()
+		if c.opts.SkipUnexported && !c.opts.PublicSurfaceOnly {
+			n = c.leadingFields(v1.Type())
+		}
+		equal, reason := c.partsEqual(n, depth, func(c *comparer, i int) (bool, string) {
+			return c.fieldEqual(v1, v2, i, depth)
+		})
+		if !equal && !c.all {
+			return false, reason
+		}
+		if c.opts.PublicSurfaceOnly && !c.inMethods {
+			if eq, r := c.publicMethodsEqual(v1, v2, depth); !eq {
+				return false, r
+			}
+		}
+		return equal, ""
 	case reflect.Map:
-		if v1.IsNil() != v2.IsNil() {
+		if c.schema {
+			return c.mapSchema(v1, v2, depth)
+		}
+		if !c.ignoreNil() && v1.IsNil() != v2.IsNil() {
 			return false, "one map is nil, one is not"
 		}
 		if v1.Len() != v2.Len() {
@@ -129,45 +329,244 @@ func deepValueEqual(v1, v2 reflect.Value, visited map[visit]bool, depth int, ski
 		if v1.Pointer() == v2.Pointer() {
 			return true, ""
 		}
-		for _, k := range v1.MapKeys() {
-			if equal, reason := deepValueEqual(v1.MapIndex(k), v2.MapIndex(k), visited, depth+1, skipUnexported); !equal {
-				key := k.Convert(v1.Type().Key())
-				return false, fmt.Sprintf("[%+v] %s", key, reason)
+		if c.opts.MapPointerKeysByValue && v1.Type().Key().Kind() == reflect.Ptr {
+			return c.mapByPointerKeys(v1, v2, depth)
+		}
+		if c.opts.CaseInsensitiveMapKeys && v1.Type().Key().Kind() == reflect.String {
+			return c.mapCaseInsensitive(v1, v2, depth)
+		}
+		result := true
+		for _, k := range c.mapKeys(v1) {
+			if equal, reason := c.descend(keyElem(k), v1.MapIndex(k), v2.MapIndex(k), depth, c.mapElemEqual); !equal {
+				if !c.all {
+					return false, reason
+				}
+				result = false
 			}
 		}
-		return true, ""
+		return result, ""
 	case reflect.Func:
 		if v1.IsNil() && v2.IsNil() {
 			return true, ""
 		}
+		if c.opts.FuncsByPointer {
+			if v1.Pointer() == v2.Pointer() {
+				return true, ""
+			}
+			return false, "functions differ"
+		}
 		// Can't do better than this:
 		return false, "non-nil functions never compare equal"
 	default:
-		// Normal equality suffices
-		if v1.Interface() == v2.Interface() {
+		if c.opts.ShapeOnly {
 			return true, ""
 		}
-		return false, "scalar values differ"
+		return c.scalarEqual(v1, v2)
 	}
 }
 
+// scalarEqual compares values of the scalar kinds with ==, by kind, so
+// values obtained through unexported fields (which can't be converted with
+// Interface) are compared too.
+func (c *comparer) scalarEqual(v1, v2 reflect.Value) (bool, string) {
+	var equal bool
+	switch k := v1.Kind(); {
+	case k == reflect.Bool:
+		equal = v1.Bool() == v2.Bool()
+	case isInt(k):
+		equal = v1.Int() == v2.Int()
+	case isUint(k):
+		equal = v1.Uint() == v2.Uint()
+	case k == reflect.Complex64 || k == reflect.Complex128:
+		equal = v1.Complex() == v2.Complex()
+	case k == reflect.String:
+		equal = c.canonicalString(v1.String()) == c.canonicalString(v2.String())
+	case k == reflect.Chan || k == reflect.UnsafePointer:
+		equal = v1.Pointer() == v2.Pointer()
+	default:
+		return false, "values are not comparable"
+	}
+	if equal {
+		return true, ""
+	}
+	return false, c.scalarReason(v1, v2)
+}
+
+// canonicalString returns the canonical form of s from StringAliases, or s
+// itself.
+func (c *comparer) canonicalString(s string) string {
+	if canonical, ok := c.opts.StringAliases[s]; ok {
+		return canonical
+	}
+	return s
+}
+
+// elemsEqual compares the elements of arrays or slices of the same length.
+func (c *comparer) elemsEqual(v1, v2 reflect.Value, depth int) (bool, string) {
+	return c.partsEqual(v1.Len(), depth, func(c *comparer, i int) (bool, string) {
+		elem := indexElem(i)
+		equal, reason := c.descend(elem, v1.Index(i), v2.Index(i), depth, c.deepValueEqual)
+		if reason != "" && v1.Kind() == reflect.Array {
+			// array element reasons don't start with the index, unlike
+			// slice ones; the path of the difference still holds it
+			reason = strings.TrimPrefix(reason, c.elemString(elem)+" ")
+		}
+		return equal, reason
+	})
+}
+
+// partsEqual compares the n parts (fields or elements) of the compared
+// values with eq, in order, stopping at the first difference unless all
+// differences are looked for. Those of the compared values themselves are
+// compared in parallel with Parallel.
+func (c *comparer) partsEqual(n, depth int, eq func(c *comparer, i int) (bool, string)) (bool, string) {
+	if depth == 0 && c.parallel(n) {
+		return c.parallelEqual(n, eq)
+	}
+	result := true
+	for i := 0; i < n; i++ {
+		if equal, reason := eq(c, i); !equal {
+			if !c.all {
+				return false, reason
+			}
+			result = false
+		}
+	}
+	return result, ""
+}
+
+// leadingFields returns the number of fields of the struct type t before
+// its first unexported field which isn't ignored: SkipUnexported stops
+// there.
+func (c *comparer) leadingFields(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := field.Name; (name[0] < 'A' || name[0] > 'Z') && !c.ignored(field.Type) {
+			return i
+		}
+	}
+	return t.NumField()
+}
+
+// fieldEqual compares the i-th fields of the structs v1 and v2 of the same
+// type. Skipped fields are equal.
+func (c *comparer) fieldEqual(v1, v2 reflect.Value, i, depth int) (bool, string) {
+	field := v1.Type().Field(i)
+	if c.ignored(field.Type) {
+		return true, ""
+	}
+	name := field.Name
+	if name[0] < 'A' || name[0] > 'Z' {
+		if c.opts.PublicSurfaceOnly || c.opts.SkipUnexported || c.opts.SkipUnexportedIn[v1.Type()] {
+			return true, ""
+		}
+		if pkg := c.opts.SkipForeignUnexported; pkg != "" {
+			if field.PkgPath != pkg {
+				return true, ""
+			}
+			if f1, ok1 := exposeField(v1, i); ok1 {
+				if f2, ok2 := exposeField(v2, i); ok2 {
+					return c.descend(fieldElem(name), f1, f2, depth, c.deepValueEqual)
+				}
+			}
+		}
+		return c.elemDiff(fieldElem(name), v1.Field(i), v2.Field(i), "unexported")
+	}
+	if c.opts.Subset && c.isEmpty(v1.Field(i)) {
+		return true, ""
+	}
+	if c.opts.NamedComparators != nil {
+		if cmp := tagComparator(field); cmp != "" {
+			return c.descend(fieldElem(name), v1.Field(i), v2.Field(i), depth, c.namedEqual(cmp))
+		}
+	}
+	return c.descend(fieldElem(name), v1.Field(i), v2.Field(i), depth, c.deepValueEqual)
+}
+
+// exposeField returns the unexported i-th field of the struct v as a value
+// which can be converted with Interface, through unsafe. It fails if v
+// itself was obtained through an unexported field which wasn't exposed.
+func exposeField(v reflect.Value, i int) (reflect.Value, bool) {
+	v, ok := addressable(v)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	f := v.Field(i)
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem(), true
+}
+
+// isEmpty tells if a struct field of the expected value is unset and so
+// skipped with Subset.
+func (c *comparer) isEmpty(v reflect.Value) bool {
+	if c.opts.EmptyFunc != nil && v.CanInterface() {
+		return c.opts.EmptyFunc(v.Interface())
+	}
+	return v.IsZero()
+}
+
+// isEmptyContainer tells if v is a slice or a map without elements, which
+// equals a nil interface with NilInterfaceEqualsEmptySlice.
+func isEmptyContainer(v reflect.Value) bool {
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0
+}
+
+// ignoreNil tells if nil slices and maps equal empty ones.
+func (c *comparer) ignoreNil() bool {
+	return c.opts.IgnoreSliceNil || c.opts.NormalizeNils
+}
+
+// nilOrEmptyPointer tells if the pointer v to a slice or a map is nil or
+// points to an empty one, for NormalizeNils.
+func nilOrEmptyPointer(v reflect.Value) bool {
+	if k := v.Type().Elem().Kind(); k != reflect.Slice && k != reflect.Map {
+		return false
+	}
+	return v.IsNil() || v.Elem().Len() == 0
+}
+
+// bothOrNone compares values by a property (like being nil) which only
+// one of them has, returning the reason if they differ.
+func bothOrNone(has1, has2 bool, reason string) (bool, string) {
+	if has1 == has2 {
+		return true, ""
+	}
+	return false, reason
+}
+
+// scalarReason returns the reason for differing scalar values.
+func (c *comparer) scalarReason(v1, v2 reflect.Value) string {
+	if c.opts.GoSyntax {
+		// fmt formats values obtained through unexported fields too,
+		// only without calling their GoString methods
+		return fmt.Sprintf("scalar values differ: %s != %s", c.clip(fmt.Sprintf("%#v", v1)), c.clip(fmt.Sprintf("%#v", v2)))
+	}
+	if v1.Kind() == reflect.String && len(c.opts.StringAliases) > 0 {
+		// the canonical forms differ, show what they were mapped from
+		return fmt.Sprintf("scalar values differ: %s != %s", c.clip(fmt.Sprintf("%q", v1.String())), c.clip(fmt.Sprintf("%q", v2.String())))
+	}
+	return "scalar values differ"
+}
+
+// clip truncates the value formatted as s in a reason to MaxReasonLen.
+func (c *comparer) clip(s string) string {
+	if c.opts.MaxReasonLen <= 0 {
+		return s
+	}
+	return truncate(s, c.opts.MaxReasonLen)
+}
+
 // Compare tests for deep equality. It uses normal == equality where
 // possible but will scan elements of arrays, slices, maps, and fields of
 // structs. In maps, keys are compared with == but elements use deep
 // equality. DeepEqual correctly handles recursive types. Functions are equal
 // only if they are both nil.
 // An empty slice is not equal to a nil slice.
+// bytes.Buffer and strings.Builder are compared by their contents,
+// sync/atomic types by their loaded values, time.Location by name,
+// text/template and html/template templates by their parsed sources.
 // If unexported field is found, return false, 'struct.NAME unexported'
 func Compare(a1, a2 interface{}) (bool, string) {
-	if a1 == nil || a2 == nil {
-		return a1 == a2, "nil values are of different types"
-	}
-	v1 := reflect.ValueOf(a1)
-	v2 := reflect.ValueOf(a2)
-	if v1.Type() != v2.Type() {
-		return false, "values are of different types"
-	}
-	return deepValueEqual(v1, v2, make(map[visit]bool), 0, false)
+	return CompareWithOptions(a1, a2, Options{})
 }
 
 // CompareS tests for deep equality. It uses normal == equality where
@@ -178,13 +577,104 @@ func Compare(a1, a2 interface{}) (bool, string) {
 // An empty slice is not equal to a nil slice.
 // If unexported field is found, skip this field
 func CompareS(a1, a2 interface{}) (bool, string) {
-	if a1 == nil || a2 == nil {
-		return a1 == a2, "nil values are of different types"
+	return CompareWithOptions(a1, a2, Options{SkipUnexported: true})
+}
+
+// CompareGoString tests for deep equality like Compare, but the reason for
+// differing scalar values includes them in Go syntax:
+// 'scalar values differ: "1" != "2"'.
+func CompareGoString(a1, a2 interface{}) (bool, string) {
+	return CompareWithOptions(a1, a2, Options{GoSyntax: true})
+}
+
+// CompareSchema tests that actual matches what's set in reference: like
+// CompareSubset, struct fields of reference with the zero value are
+// skipped, including zero nested structs and nil pointers, while set
+// ones are compared recursively. Map values with the zero value are
+// skipped too and the other keys of reference must be in actual, extra
+// keys of actual are allowed. Slices and arrays are compared element by
+// element, with the same rules inside the elements.
+func CompareSchema(reference, actual interface{}) (bool, string) {
+	opts := Options{Subset: true}
+	c := newComparer(&opts)
+	c.schema = true
+	return c.compareRoot(reference, actual)
+}
+
+// CompareSubset tests that actual matches the fields of expected which are
+// set. Empty (zero valued) struct fields of expected are skipped at any
+// depth, other values are compared like with Compare.
+func CompareSubset(expected, actual interface{}) (bool, string) {
+	return CompareWithOptions(expected, actual, Options{Subset: true})
+}
+
+// CompareWithOptions tests for deep equality like Compare, with the
+// behaviour adjusted by opts.
+func CompareWithOptions(a1, a2 interface{}, opts Options) (bool, string) {
+	if opts.Timeout > 0 {
+		return newComparer(&opts).compareTimeout(a1, a2)
+	}
+	return newComparer(&opts).compareRoot(a1, a2)
+}
+
+// interchangeable tells if values of the different types t1 and t2 are
+// compared anyway, due to the options.
+func (c *comparer) interchangeable(t1, t2 reflect.Type) bool {
+	return c.opts.StringBytesInterchangeable && stringBytesPair(t1, t2) ||
+		c.opts.StructsByName && byNamePair(t1, t2) ||
+		c.opts.SignedUnsignedEqual && signedUnsignedPair(t1, t2) ||
+		c.opts.LenientPointers && c.pointerValuePair(t1, t2)
+}
+
+// sameReference tells if v1 and v2 of the same type are the same non-nil
+// pointer, map or slice (of the same length), so a value is compared to
+// itself, like in idempotency tests.
+func sameReference(v1, v2 reflect.Value) bool {
+	switch v1.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return !v1.IsNil() && v1.Pointer() == v2.Pointer()
+	case reflect.Slice:
+		return !v1.IsNil() && v1.Pointer() == v2.Pointer() && v1.Len() == v2.Len()
 	}
+	return false
+}
+
+// compareRoot compares the values passed to the package functions. A value
+// compared to itself is equal without looking into it, unless a tree is
+// built.
+func (c *comparer) compareRoot(a1, a2 interface{}) (equal bool, reason string) {
 	v1 := reflect.ValueOf(a1)
 	v2 := reflect.ValueOf(a2)
-	if v1.Type() != v2.Type() {
-		return false, "values are of different types"
+	if c.opts.JSONPathPaths && !c.all {
+		c.trackFirst = true
+	}
+	if a1 == Any {
+		equal = true
+	} else if a1 == nil || a2 == nil {
+		if c.opts.NilInterfaceEqualsEmptySlice && (isEmptyContainer(v1) || isEmptyContainer(v2)) {
+			equal = true
+		} else {
+			equal, reason = bothOrNone(a1 == nil, a2 == nil, "nil values are of different types")
+		}
+	} else if v1.Type() != v2.Type() && !c.interchangeable(v1.Type(), v2.Type()) {
+		equal, reason = false, fmt.Sprintf("values are of different types: %v vs %v", v1.Type(), v2.Type())
+	} else if c.node == nil && v1.Type() == v2.Type() && sameReference(v1, v2) {
+		equal = true
+	} else {
+		equal, reason = c.deepValueEqual(v1, v2, 0)
+	}
+	if !equal && reason != "" {
+		if c.trackFirst && c.first == nil {
+			d := c.newDifference(v1, v2, reason)
+			c.first = &d
+		}
+		if c.all {
+			c.difference(v1, v2, reason)
+			reason = ""
+		}
+	}
+	if c.opts.JSONPathPaths && reason != "" && c.first != nil && len(c.first.Path) > 0 {
+		reason = jsonPath(c.first.Path) + " " + c.first.Reason
 	}
-	return deepValueEqual(v1, v2, make(map[visit]bool), 0, true)
+	return equal, reason
 }
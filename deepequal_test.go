@@ -6,7 +6,10 @@ package deepequal
 
 import (
 	"math"
+	"reflect"
+	"strconv"
 	"testing"
+	"unsafe"
 )
 
 type testStruct struct {
@@ -53,6 +56,13 @@ func TestCompare(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name:       "Non Equal array",
+			a1:         [3]int{0, 1, 2},
+			a2:         [3]int{0, 1, 3},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
 		{
 			name:       "int",
 			a1:         2,
@@ -291,3 +301,524 @@ func TestCompareS(t *testing.T) {
 		})
 	}
 }
+
+type testNode struct {
+	V    int
+	Next *testNode
+}
+
+func TestCompareMapOfPointers(t *testing.T) {
+	p := &testNode{V: 1}
+	q := &testNode{V: 2}
+	tests := []struct {
+		name       string
+		a1         map[string]*testNode
+		a2         map[string]*testNode
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "same pointers",
+			a1:   map[string]*testNode{"a": p, "b": q},
+			a2:   map[string]*testNode{"a": p, "b": q},
+			want: true,
+		},
+		{
+			name: "equal copies",
+			a1:   map[string]*testNode{"a": p, "b": q},
+			a2:   map[string]*testNode{"a": {V: 1}, "b": {V: 2}},
+			want: true,
+		},
+		{
+			name:       "shared pointer under other key",
+			a1:         map[string]*testNode{"a": p, "b": p},
+			a2:         map[string]*testNode{"a": p, "b": q},
+			want:       false,
+			wantReason: "[b] struct.V scalar values differ",
+		},
+		{
+			name:       "pointers to shared nodes",
+			a1:         map[string]*testNode{"a": {V: 1, Next: q}, "b": p},
+			a2:         map[string]*testNode{"a": {V: 1, Next: p}, "b": p},
+			want:       false,
+			wantReason: "[a] struct.Next struct.V scalar values differ",
+		},
+		{
+			name:       "nil value",
+			a1:         map[string]*testNode{"a": nil},
+			a2:         map[string]*testNode{"a": p},
+			want:       false,
+			wantReason: "[a] one pointer is nil, the other is not",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+
+	// swapped pointers differ under both keys, so only check the result
+	if got, _ := Compare(map[string]*testNode{"a": p, "b": q}, map[string]*testNode{"a": q, "b": p}); got {
+		t.Errorf("Compare() swapped pointers got = %v, want false", got)
+	}
+}
+
+type testGoSyntax struct {
+	Name  string
+	Value interface{}
+	F     float64
+	Color testColor
+}
+
+func TestCompareGoString(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal",
+			a1:   testGoSyntax{Name: "a", Value: "1"},
+			a2:   testGoSyntax{Name: "a", Value: "1"},
+			want: true,
+		},
+		{
+			name:       "string",
+			a1:         testGoSyntax{Name: "a", Value: "1"},
+			a2:         testGoSyntax{Name: "b", Value: "1"},
+			want:       false,
+			wantReason: `struct.Name scalar values differ: "a" != "b"`,
+		},
+		{
+			name:       "interface int",
+			a1:         testGoSyntax{Name: "a", Value: 1},
+			a2:         testGoSyntax{Name: "a", Value: 2},
+			want:       false,
+			wantReason: `struct.Value scalar values differ: 1 != 2`,
+		},
+		{
+			name:       "float",
+			a1:         testGoSyntax{F: 1.5},
+			a2:         testGoSyntax{F: 2},
+			want:       false,
+			wantReason: `struct.F scalar values differ: 1.5 != 2`,
+		},
+		{
+			name:       "named type",
+			a1:         []testColor{testRed},
+			a2:         []testColor{testGreen},
+			want:       false,
+			wantReason: `[0] scalar values differ: 0 != 1`,
+		},
+		{
+			name:       "map value",
+			a1:         map[string]string{"k": "1"},
+			a2:         map[string]string{"k": "2"},
+			want:       false,
+			wantReason: `[k] scalar values differ: "1" != "2"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareGoString(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("CompareGoString() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareGoString() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareGoString_Unexported(t *testing.T) {
+	// values obtained through unexported fields can't be used with Interface
+	v := reflect.ValueOf(testStructS{_name: "a"}).Field(0)
+	w := reflect.ValueOf(testStructS{_name: "b"}).Field(0)
+	c := newComparer(&Options{GoSyntax: true})
+	if got := c.scalarReason(v, w); got != `scalar values differ: "a" != "b"` {
+		t.Errorf("scalarReason() = '%v'", got)
+	}
+}
+
+func newTestRing(values ...int) []*testNode {
+	nodes := make([]*testNode, len(values))
+	for i, v := range values {
+		nodes[i] = &testNode{V: v}
+	}
+	for i, n := range nodes {
+		n.Next = nodes[(i+1)%len(nodes)]
+	}
+	return nodes
+}
+
+func TestCompareCycles(t *testing.T) {
+	r1 := newTestRing(1, 2, 3)
+	r2 := newTestRing(1, 2, 3)
+	same := newTestRing(1, 1, 1)
+	pair := newTestRing(1, 1)
+	r3 := newTestRing(1, 1, 2)
+	r4 := newTestRing(1, 1, 2)
+	tests := []struct {
+		name       string
+		a1         *testNode
+		a2         *testNode
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "same offset",
+			a1:   r1[0],
+			a2:   r2[0],
+			want: true,
+		},
+		{
+			name: "same offset, second node",
+			a1:   r1[1],
+			a2:   r2[1],
+			want: true,
+		},
+		{
+			name: "itself",
+			a1:   r1[2],
+			a2:   r1[2],
+			want: true,
+		},
+		{
+			name:       "different offsets",
+			a1:         r1[0],
+			a2:         r2[1],
+			want:       false,
+			wantReason: "struct.V scalar values differ",
+		},
+		{
+			name:       "different offsets in the same ring",
+			a1:         r1[0],
+			a2:         r1[2],
+			want:       false,
+			wantReason: "struct.V scalar values differ",
+		},
+		{
+			name:       "differ after entry",
+			a1:         r3[0],
+			a2:         r4[1],
+			want:       false,
+			wantReason: "struct.Next struct.V scalar values differ",
+		},
+		{
+			name:       "differ after wrap around",
+			a1:         r3[1],
+			a2:         r4[0],
+			want:       false,
+			wantReason: "struct.Next struct.V scalar values differ",
+		},
+		{
+			name: "uniform rings of different lengths",
+			a1:   same[0],
+			a2:   pair[1],
+			want: true,
+		},
+		{
+			name: "uniform ring from different offsets",
+			a1:   same[0],
+			a2:   same[2],
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+type testEnvelope struct {
+	Payload interface{}
+	Items   []testEnvelope
+}
+
+func TestCompareTypeMismatchPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		wantReason string
+	}{
+		{
+			name:       "top level",
+			a1:         1,
+			a2:         "1",
+			wantReason: "values are of different types: int vs string",
+		},
+		{
+			name:       "struct field",
+			a1:         testEnvelope{Payload: 1},
+			a2:         testEnvelope{Payload: "1"},
+			wantReason: "struct.Payload values are of differing types: int vs string",
+		},
+		{
+			name:       "nested",
+			a1:         testEnvelope{Items: []testEnvelope{{}, {Payload: map[string]interface{}{"k": 1}}}},
+			a2:         testEnvelope{Items: []testEnvelope{{}, {Payload: map[string]interface{}{"k": []int{1}}}}},
+			wantReason: "struct.Items [1] struct.Payload [k] values are of differing types: int vs []int",
+		},
+		{
+			name:       "heterogeneous slice",
+			a1:         []interface{}{1, "a", 2.0},
+			a2:         []interface{}{1, 2, 2.0},
+			wantReason: "[1] values are of differing types: string vs int",
+		},
+		{
+			name:       "heterogeneous array",
+			a1:         [3]interface{}{1, "a", 2.0},
+			a2:         [3]interface{}{1, "a", float32(2)},
+			wantReason: "values are of differing types: float64 vs float32",
+		},
+		{
+			name:       "heterogeneous slice nil element",
+			a1:         []interface{}{1, nil},
+			a2:         []interface{}{1, "a"},
+			wantReason: "[1] both interfaces must be nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got {
+				t.Errorf("Compare() got = %v, want false", got)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareSliceOfPointers(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal with nils",
+			a1:   []*testNode{{V: 1}, nil, {V: 3}},
+			a2:   []*testNode{{V: 1}, nil, {V: 3}},
+			want: true,
+		},
+		{
+			name:       "second nil",
+			a1:         []*testNode{{V: 1}, {V: 2}},
+			a2:         []*testNode{{V: 1}, nil},
+			want:       false,
+			wantReason: "[1] one pointer is nil, the other is not",
+		},
+		{
+			name:       "first nil",
+			a1:         []*int{nil},
+			a2:         []*int{new(int)},
+			want:       false,
+			wantReason: "[0] one pointer is nil, the other is not",
+		},
+		{
+			name:       "values differ",
+			a1:         []*testNode{nil, {V: 2}},
+			a2:         []*testNode{nil, {V: 3}},
+			want:       false,
+			wantReason: "[1] struct.V scalar values differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareNilPointers(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "untyped nils",
+			a1:   nil,
+			a2:   nil,
+			want: true,
+		},
+		{
+			name:       "untyped nil",
+			a1:         nil,
+			a2:         (*testStruct)(nil),
+			want:       false,
+			wantReason: "nil values are of different types",
+		},
+		{
+			name: "top level both nil",
+			a1:   (*testStruct)(nil),
+			a2:   (*testStruct)(nil),
+			want: true,
+		},
+		{
+			name:       "top level first nil",
+			a1:         (*testStruct)(nil),
+			a2:         &testStruct{},
+			want:       false,
+			wantReason: "one pointer is nil, the other is not",
+		},
+		{
+			name:       "top level second nil",
+			a1:         &testStruct{},
+			a2:         (*testStruct)(nil),
+			want:       false,
+			wantReason: "one pointer is nil, the other is not",
+		},
+		{
+			name: "nested both nil",
+			a1:   testNode{V: 1},
+			a2:   testNode{V: 1},
+			want: true,
+		},
+		{
+			name:       "nested one nil",
+			a1:         testNode{V: 1, Next: &testNode{V: 2}},
+			a2:         testNode{V: 1, Next: &testNode{V: 2, Next: &testNode{}}},
+			want:       false,
+			wantReason: "struct.Next struct.Next one pointer is nil, the other is not",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+type testUnexportedScalars struct {
+	n int
+	u uint8
+	s string
+	b bool
+	c complex64
+	p unsafe.Pointer
+}
+
+func TestCompareUnexportedScalars(t *testing.T) {
+	x := 1
+	base := testUnexportedScalars{n: 1, u: 2, s: "a", b: true, c: 1i, p: unsafe.Pointer(&x)}
+	tests := []struct {
+		name   string
+		field  string
+		change func(s *testUnexportedScalars)
+	}{
+		{name: "equal", change: func(s *testUnexportedScalars) {}},
+		{name: "int", field: "n", change: func(s *testUnexportedScalars) { s.n = 2 }},
+		{name: "uint", field: "u", change: func(s *testUnexportedScalars) { s.u = 3 }},
+		{name: "string", field: "s", change: func(s *testUnexportedScalars) { s.s = "b" }},
+		{name: "bool", field: "b", change: func(s *testUnexportedScalars) { s.b = false }},
+		{name: "complex", field: "c", change: func(s *testUnexportedScalars) { s.c = 2i }},
+		{name: "unsafe pointer", field: "p", change: func(s *testUnexportedScalars) { s.p = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := base
+			tt.change(&changed)
+			// Interface panics for values obtained through unexported fields
+			v1, v2 := reflect.ValueOf(base), reflect.ValueOf(changed)
+			c := newComparer(&Options{})
+			for i := 0; i < v1.NumField(); i++ {
+				name := v1.Type().Field(i).Name
+				got, gotReason := c.deepValueEqual(v1.Field(i), v2.Field(i), 0)
+				if want := name != tt.field; got != want {
+					t.Errorf("deepValueEqual(%s) got = %v, want %v", name, got, want)
+				} else if !got && gotReason != "scalar values differ" {
+					t.Errorf("deepValueEqual(%s) got1 = '%v', want 'scalar values differ'", name, gotReason)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareSameReference(t *testing.T) {
+	s := &testStruct{Name: "a", S: []int{1, 2}, M: map[int]string{1: "a"}}
+	tests := []struct {
+		name string
+		a    interface{}
+	}{
+		{name: "pointer", a: s},
+		{name: "slice", a: s.S},
+		{name: "map", a: s.M},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, reason := Compare(tt.a, tt.a); !got || reason != "" {
+				t.Errorf("Compare() = %v, '%v', want true", got, reason)
+			}
+		})
+	}
+	if got, reason := Compare(s.S[:1], s.S); got || reason != "slices have different lengths" {
+		t.Errorf("Compare() = %v, '%v', want different lengths", got, reason)
+	}
+}
+
+func benchLargeStruct() *testStruct {
+	s := &testStruct{Name: "large", S: make([]int, 10000), M: make(map[int]string, 1000)}
+	for i := range s.S {
+		s.S[i] = i
+	}
+	for i := 0; i < 1000; i++ {
+		s.M[i] = strconv.Itoa(i)
+	}
+	return s
+}
+
+func BenchmarkCompareSelf(b *testing.B) {
+	s := benchLargeStruct()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if equal, reason := Compare(s, s); !equal {
+			b.Fatal(reason)
+		}
+	}
+}
+
+func BenchmarkCompareLarge(b *testing.B) {
+	s1, s2 := benchLargeStruct(), benchLargeStruct()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if equal, reason := Compare(s1, s2); !equal {
+			b.Fatal(reason)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package deepequal
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Difference is a difference found between compared values.
+type Difference struct {
+	// Path locates the differing values, it's empty for the compared
+	// values themselves.
+	Path []PathElem
+	// Reason describes the difference, like 'scalar values differ'.
+	Reason string
+	// A and B are the differing values. Values which can't be converted
+	// with Interface are held in their formatted form.
+	A, B interface{}
+}
+
+// String formats the difference like the reasons returned by Compare:
+// 'struct.S [2] scalar values differ'.
+func (d Difference) String() string {
+	if len(d.Path) == 0 {
+		return d.Reason
+	}
+	return formatPath(d.Path) + " " + d.Reason
+}
+
+// formatPath formats the path like it's shown in reasons.
+func formatPath(path []PathElem) string {
+	elems := make([]string, len(path))
+	for i, e := range path {
+		elems[i] = e.String()
+	}
+	return strings.Join(elems, " ")
+}
+
+// newDifference returns the difference of v1 and v2 at the current path.
+func (c *comparer) newDifference(v1, v2 reflect.Value, reason string) Difference {
+	path := make([]PathElem, len(c.path))
+	copy(path, c.path)
+	return Difference{Path: path, Reason: reason, A: valueInterface(v1), B: valueInterface(v2)}
+}
+
+// compareAll compares a1 and a2 passing every difference found to report.
+func compareAll(a1, a2 interface{}, opts Options, report func(d Difference)) bool {
+	c := newComparer(&opts)
+	c.all = true
+	c.report = report
+	equal, _ := c.compareRoot(a1, a2)
+	return equal
+}
+
+// CompareTo compares a1 and a2 like Compare, but writes every difference
+// found to w, one per line, as soon as it's found. It returns the first
+// write error, after which nothing more is written.
+func CompareTo(w io.Writer, a1, a2 interface{}) (bool, error) {
+	var err error
+	equal := compareAll(a1, a2, Options{}, func(d Difference) {
+		if err == nil {
+			_, err = io.WriteString(w, d.String()+"\n")
+		}
+	})
+	return equal, err
+}
+
+// CompareChan compares a1 and a2 like Compare in a goroutine, sending
+// every difference found to the first channel, which is closed when the
+// comparison is done. Then the second channel receives whether the values
+// are equal. The first channel must be drained, use CompareChanContext to
+// stop reading early.
+func CompareChan(a1, a2 interface{}) (<-chan Difference, <-chan bool) {
+	return CompareChanContext(context.Background(), a1, a2)
+}
+
+// CompareChanContext is like CompareChan, but stops sending differences
+// when ctx is done, so the caller may stop reading them. The comparison
+// still completes in the background and its result is sent.
+func CompareChanContext(ctx context.Context, a1, a2 interface{}) (<-chan Difference, <-chan bool) {
+	diffs := make(chan Difference)
+	result := make(chan bool, 1)
+	go func() {
+		equal := compareAll(a1, a2, Options{}, func(d Difference) {
+			select {
+			case diffs <- d:
+			case <-ctx.Done():
+			}
+		})
+		close(diffs)
+		result <- equal
+		close(result)
+	}()
+	return diffs, result
+}
+
+// Result is the outcome of CompareResult.
+type Result struct {
+	// Equal and Reason are the results of Compare.
+	Equal  bool
+	Reason string
+	// Path, A and B are those of the first difference found (see
+	// Difference), they are empty for equal values.
+	Path []PathElem
+	A, B interface{}
+}
+
+// CompareResult compares a1 and a2 like Compare and returns everything
+// known about the first difference in one value, for table-driven tests.
+func CompareResult(a1, a2 interface{}) Result {
+	d, differ := firstDifference(a1, a2, Options{})
+	if !differ {
+		return Result{Equal: true}
+	}
+	return Result{Reason: d.String(), Path: d.Path, A: d.A, B: d.B}
+}
+
+// CompareAccessor compares a1 and a2 like Compare. If they differ, it
+// returns a function which finds the value at the path of the first
+// difference in root, a value of the same shape (like a fresh copy of a1
+// or a2). The function returns nil if there is no such value in root.
+func CompareAccessor(a1, a2 interface{}) (bool, func(root interface{}) interface{}) {
+	d, differ := firstDifference(a1, a2, Options{})
+	if !differ {
+		return true, nil
+	}
+	return false, func(root interface{}) interface{} {
+		v, ok := follow(reflect.ValueOf(root), d.Path)
+		if !ok {
+			return nil
+		}
+		return valueInterface(v)
+	}
+}
+
+// CompareCommonRoot compares a1 and a2 like Compare, but finds every
+// difference and returns the longest path containing all of them, like
+// 'struct.Config' if only fields of Config differ. It's empty if the
+// values are equal or differ at the top.
+func CompareCommonRoot(a1, a2 interface{}) (bool, string) {
+	var root []PathElem
+	first := true
+	equal := compareAll(a1, a2, Options{}, func(d Difference) {
+		if first {
+			root = d.Path
+			first = false
+			return
+		}
+		n := 0
+		for n < len(root) && n < len(d.Path) && samePathElem(root[n], d.Path[n]) {
+			n++
+		}
+		root = root[:n]
+	})
+	if equal {
+		return true, ""
+	}
+	return false, formatPath(root)
+}
+
+func samePathElem(e1, e2 PathElem) bool {
+	return e1.Kind == e2.Kind && e1.String() == e2.String()
+}
+
+// CompareGrouped compares a1 and a2 like Compare, but finds every
+// difference and groups them by the top-level struct field they are
+// found in, by field name. The reasons in a group are relative to the
+// field, like '[cpu] struct.Min scalar values differ' for
+// 'struct.Limits [cpu] struct.Min scalar values differ'. Fields without
+// differences are omitted, so the result is empty if the values are
+// equal. Differences under an element or a key of other values are
+// grouped by it, like '[2]', those of the values themselves under the
+// empty name.
+func CompareGrouped(a1, a2 interface{}) map[string][]string {
+	groups := make(map[string][]string)
+	compareAll(a1, a2, Options{}, func(d Difference) {
+		var group string
+		if len(d.Path) > 0 {
+			if d.Path[0].Kind == PathField {
+				group = d.Path[0].Name
+			} else {
+				group = d.Path[0].String()
+			}
+			d.Path = d.Path[1:]
+		}
+		groups[group] = append(groups[group], d.String())
+	})
+	return groups
+}
+
+// CompareFieldMap compares structs (or pointers to structs) a1 and a2
+// field by field and returns whether each exported field is equal, like
+// with Compare, by field name. Differences nested in a field make it
+// false. Other values are compared as a whole, with the result under the
+// empty name.
+func CompareFieldMap(a1, a2 interface{}) map[string]bool {
+	v1, v2 := reflect.ValueOf(a1), reflect.ValueOf(a2)
+	if a1 != nil && a2 != nil && v1.Type() == v2.Type() && v1.Kind() == reflect.Ptr && !v1.IsNil() && !v2.IsNil() {
+		v1, v2 = v1.Elem(), v2.Elem()
+	}
+	if a1 == nil || a2 == nil || v1.Type() != v2.Type() || v1.Kind() != reflect.Struct {
+		equal, _ := Compare(a1, a2)
+		return map[string]bool{"": equal}
+	}
+	c := newComparer(&Options{})
+	fields := make(map[string]bool, v1.NumField())
+	for i := 0; i < v1.NumField(); i++ {
+		name := v1.Type().Field(i).Name
+		if name[0] < 'A' || name[0] > 'Z' {
+			continue
+		}
+		fields[name], _ = c.probe().deepValueEqual(v1.Field(i), v2.Field(i), 1)
+	}
+	return fields
+}
@@ -0,0 +1,524 @@
+package deepequal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testShared struct {
+	A, B *testLimits
+}
+
+func TestCompareTo(t *testing.T) {
+	p, q := &testLimits{1, 2}, &testLimits{1, 3}
+	tests := []struct {
+		name string
+		a1   interface{}
+		a2   interface{}
+		want bool
+		out  string
+	}{
+		{
+			name: "equal",
+			a1:   testStruct{Name: "a", S: []int{1}},
+			a2:   testStruct{Name: "a", S: []int{1}},
+			want: true,
+		},
+		{
+			name: "differ",
+			a1:   testStruct{Name: "a", S: []int{1, 2, 3}, M: map[int]string{1: "1"}},
+			a2:   testStruct{Name: "b", S: []int{1, 5, 6}, M: map[int]string{1: "2"}},
+			want: false,
+			out: "struct.Name scalar values differ\n" +
+				"struct.S [1] scalar values differ\n" +
+				"struct.S [2] scalar values differ\n" +
+				"struct.M [1] scalar values differ\n",
+		},
+		{
+			name: "top level",
+			a1:   1,
+			a2:   "1",
+			want: false,
+			out:  "values are of different types: int vs string\n",
+		},
+		{
+			name: "shared pointers",
+			a1:   testShared{A: p, B: p},
+			a2:   testShared{A: q, B: q},
+			want: false,
+			out: "struct.A struct.Max scalar values differ\n" +
+				"struct.B struct.Max scalar values differ\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			got, err := CompareTo(&buf, tt.a1, tt.a2)
+			if err != nil {
+				t.Fatalf("CompareTo() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompareTo() got = %v, want %v", got, tt.want)
+			}
+			if buf.String() != tt.out {
+				t.Errorf("CompareTo() output\n%s\nwant\n%s", buf.String(), tt.out)
+			}
+		})
+	}
+}
+
+type testFailWriter struct {
+	writes int
+}
+
+func (w *testFailWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("write failed")
+}
+
+func TestCompareTo_WriteError(t *testing.T) {
+	w := &testFailWriter{}
+	got, err := CompareTo(w, []int{1, 2}, []int{3, 4})
+	if got {
+		t.Errorf("CompareTo() got = %v, want false", got)
+	}
+	if err == nil || err.Error() != "write failed" {
+		t.Errorf("CompareTo() error = %v, want 'write failed'", err)
+	}
+	if w.writes != 1 {
+		t.Errorf("CompareTo() writes = %d, want 1", w.writes)
+	}
+}
+
+func TestCompareChan(t *testing.T) {
+	tests := []struct {
+		name string
+		a1   interface{}
+		a2   interface{}
+		want bool
+		out  []string
+	}{
+		{
+			name: "equal",
+			a1:   testStruct{Name: "a", S: []int{1}},
+			a2:   testStruct{Name: "a", S: []int{1}},
+			want: true,
+		},
+		{
+			name: "differ",
+			a1:   testStruct{Name: "a", S: []int{1, 2, 3}},
+			a2:   testStruct{Name: "b", S: []int{1, 5, 6}},
+			want: false,
+			out: []string{
+				"struct.Name scalar values differ",
+				"struct.S [1] scalar values differ",
+				"struct.S [2] scalar values differ",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, result := CompareChan(tt.a1, tt.a2)
+			var out []string
+			for d := range diffs {
+				out = append(out, d.String())
+			}
+			if got := <-result; got != tt.want {
+				t.Errorf("CompareChan() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(out, tt.out) {
+				t.Errorf("CompareChan() differences = %q, want %q", out, tt.out)
+			}
+		})
+	}
+}
+
+func TestCompareChanContext_Cancel(t *testing.T) {
+	a1, a2 := make([]int, 1000), make([]int, 1000)
+	for i := range a2 {
+		a2[i] = i + 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	diffs, result := CompareChanContext(ctx, a1, a2)
+	if d := <-diffs; d.String() != "[0] scalar values differ" {
+		t.Errorf("first difference = %q", d.String())
+	}
+	cancel()
+	// the comparison finishes without anyone reading the differences
+	if got := <-result; got {
+		t.Errorf("CompareChanContext() got = %v, want false", got)
+	}
+	n := 0
+	for range diffs {
+		n++
+	}
+	if n != 0 {
+		t.Errorf("%d differences sent after cancel", n)
+	}
+}
+
+func TestCompareResult(t *testing.T) {
+	tests := []struct {
+		name string
+		a1   interface{}
+		a2   interface{}
+		want Result
+	}{
+		{
+			name: "equal",
+			a1:   testStruct{Name: "a", S: []int{1}},
+			a2:   testStruct{Name: "a", S: []int{1}},
+			want: Result{Equal: true},
+		},
+		{
+			name: "nested",
+			a1:   testStruct{Name: "a", S: []int{1, 2}},
+			a2:   testStruct{Name: "a", S: []int{1, 3}},
+			want: Result{
+				Reason: "struct.S [1] scalar values differ",
+				Path:   []PathElem{{Kind: PathField, Name: "S"}, {Kind: PathIndex, Index: 1}},
+				A:      2,
+				B:      3,
+			},
+		},
+		{
+			name: "map key",
+			a1:   map[string]int{"k": 1},
+			a2:   map[string]int{"k": 2},
+			want: Result{
+				Reason: "[k] scalar values differ",
+				Path:   []PathElem{{Kind: PathKey, Key: "k"}},
+				A:      1,
+				B:      2,
+			},
+		},
+		{
+			name: "top level",
+			a1:   1,
+			a2:   "1",
+			want: Result{Reason: "values are of different types: int vs string", Path: []PathElem{}, A: 1, B: "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareResult(tt.a1, tt.a2)
+			if got.Equal != tt.want.Equal {
+				t.Errorf("CompareResult().Equal = %v, want %v", got.Equal, tt.want.Equal)
+			}
+			if got.Reason != tt.want.Reason {
+				t.Errorf("CompareResult().Reason = '%v', want '%v'", got.Reason, tt.want.Reason)
+			}
+			if equal, reason := Compare(got.Path, tt.want.Path); !equal {
+				t.Errorf("CompareResult().Path = %v, want %v: %s", got.Path, tt.want.Path, reason)
+			}
+			if got.A != tt.want.A {
+				t.Errorf("CompareResult().A = %v, want %v", got.A, tt.want.A)
+			}
+			if got.B != tt.want.B {
+				t.Errorf("CompareResult().B = %v, want %v", got.B, tt.want.B)
+			}
+			if equal, reason := Compare(tt.a1, tt.a2); equal != got.Equal || reason != got.Reason {
+				t.Errorf("Compare() = %v, '%v', CompareResult() = %v, '%v'", equal, reason, got.Equal, got.Reason)
+			}
+		})
+	}
+}
+
+type testOrg struct {
+	Root     *testEnvelope
+	Children map[string][]testStruct
+}
+
+func (t testOrg) Size() int {
+	return len(t.Children)
+}
+
+func TestCompareAccessor(t *testing.T) {
+	tree := func(v int, s string) testOrg {
+		return testOrg{
+			Root:     &testEnvelope{Payload: map[string]interface{}{"v": v}},
+			Children: map[string][]testStruct{"a": {{Name: "x"}, {Name: s}}},
+		}
+	}
+	tests := []struct {
+		name string
+		a1   interface{}
+		a2   interface{}
+		root interface{}
+		want interface{}
+	}{
+		{
+			name: "through pointer and interface",
+			a1:   tree(1, "y"),
+			a2:   tree(2, "y"),
+			root: tree(3, "y"),
+			want: 3,
+		},
+		{
+			name: "map and slice",
+			a1:   tree(1, "y"),
+			a2:   tree(1, "z"),
+			root: tree(1, "w"),
+			want: "w",
+		},
+		{
+			name: "missing in root",
+			a1:   tree(1, "y"),
+			a2:   tree(1, "z"),
+			root: testOrg{Root: &testEnvelope{}},
+			want: nil,
+		},
+		{
+			name: "index out of range in root",
+			a1:   []testStruct{{Name: "x"}, {Name: "y"}},
+			a2:   []testStruct{{Name: "x"}, {Name: "z"}},
+			root: []testStruct{{Name: "x"}},
+			want: nil,
+		},
+		{
+			name: "top level",
+			a1:   1,
+			a2:   2,
+			root: 5,
+			want: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, get := CompareAccessor(tt.a1, tt.a2)
+			if equal || get == nil {
+				t.Fatalf("CompareAccessor() = %v, want a difference", equal)
+			}
+			if got := get(tt.root); got != tt.want {
+				t.Errorf("accessor(root) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if equal, get := CompareAccessor(tree(1, "y"), tree(1, "y")); !equal || get != nil {
+		t.Errorf("CompareAccessor() of equal values = %v, %v", equal, get != nil)
+	}
+}
+
+func TestFollow_Method(t *testing.T) {
+	v, ok := follow(reflect.ValueOf(testOrg{Children: map[string][]testStruct{"a": nil}}), []PathElem{methodElem("Size")})
+	if !ok || v.Interface() != 1 {
+		t.Errorf("follow() = %v, %v, want 1", v, ok)
+	}
+}
+
+type testLimits struct {
+	Min, Max int
+}
+
+type testDeploymentConfig struct {
+	Name   string
+	Limits map[string]testLimits
+	Tags   []string
+}
+
+type testDeployment struct {
+	Host   string
+	Config testDeploymentConfig
+}
+
+func TestCompareCommonRoot(t *testing.T) {
+	deployment := func(host string, min, max int, tag string) testDeployment {
+		return testDeployment{
+			Host: host,
+			Config: testDeploymentConfig{
+				Name:   "c",
+				Limits: map[string]testLimits{"cpu": {min, max}},
+				Tags:   []string{"a", tag},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		a1       interface{}
+		a2       interface{}
+		want     bool
+		wantRoot string
+	}{
+		{
+			name: "equal",
+			a1:   deployment("h", 1, 2, "b"),
+			a2:   deployment("h", 1, 2, "b"),
+			want: true,
+		},
+		{
+			name:     "under one field",
+			a1:       deployment("h", 1, 2, "b"),
+			a2:       deployment("h", 3, 2, "c"),
+			want:     false,
+			wantRoot: "struct.Config",
+		},
+		{
+			name:     "under a map value",
+			a1:       deployment("h", 1, 2, "b"),
+			a2:       deployment("h", 3, 4, "b"),
+			want:     false,
+			wantRoot: "struct.Config struct.Limits [cpu]",
+		},
+		{
+			name:     "single difference",
+			a1:       deployment("h", 1, 2, "b"),
+			a2:       deployment("h", 1, 2, "c"),
+			want:     false,
+			wantRoot: "struct.Config struct.Tags [1]",
+		},
+		{
+			name:     "spread",
+			a1:       deployment("h", 1, 2, "b"),
+			a2:       deployment("x", 1, 2, "c"),
+			want:     false,
+			wantRoot: "",
+		},
+		{
+			name:     "top",
+			a1:       1,
+			a2:       2,
+			want:     false,
+			wantRoot: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotRoot := CompareCommonRoot(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("CompareCommonRoot() got = %v, want %v", got, tt.want)
+			}
+			if gotRoot != tt.wantRoot {
+				t.Errorf("CompareCommonRoot() got1 = %q, want %q", gotRoot, tt.wantRoot)
+			}
+		})
+	}
+}
+
+func TestCompareFieldMap(t *testing.T) {
+	tests := []struct {
+		name string
+		a1   interface{}
+		a2   interface{}
+		want map[string]bool
+	}{
+		{
+			name: "some fields differ",
+			a1:   testDeployment{Host: "h", Config: testDeploymentConfig{Name: "a", Tags: []string{"x"}}},
+			a2:   testDeployment{Host: "h", Config: testDeploymentConfig{Name: "a", Tags: []string{"y"}}},
+			want: map[string]bool{"Host": true, "Config": false},
+		},
+		{
+			name: "pointers",
+			a1:   &testLocalState{Name: "a", count: 1},
+			a2:   &testLocalState{Name: "b", count: 1},
+			want: map[string]bool{"Name": false},
+		},
+		{
+			name: "equal",
+			a1:   testStruct{Name: "a", S: []int{1}},
+			a2:   testStruct{Name: "a", S: []int{1}},
+			want: map[string]bool{"Name": true, "S": true, "M": true},
+		},
+		{
+			name: "not structs",
+			a1:   []int{1},
+			a2:   []int{2},
+			want: map[string]bool{"": false},
+		},
+		{
+			name: "different types",
+			a1:   testStruct{},
+			a2:   &testStruct{},
+			want: map[string]bool{"": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareFieldMap(tt.a1, tt.a2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareFieldMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareGrouped(t *testing.T) {
+	p, q := &testLimits{1, 2}, &testLimits{1, 3}
+	deployment := func(host string, min int, tag string) testDeployment {
+		return testDeployment{
+			Host: host,
+			Config: testDeploymentConfig{
+				Name:   "c",
+				Limits: map[string]testLimits{"cpu": {min, 2}},
+				Tags:   []string{"a", tag},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		a1   interface{}
+		a2   interface{}
+		want map[string][]string
+	}{
+		{
+			name: "equal",
+			a1:   deployment("h", 1, "b"),
+			a2:   deployment("h", 1, "b"),
+			want: map[string][]string{},
+		},
+		{
+			name: "several fields",
+			a1:   deployment("h", 1, "b"),
+			a2:   deployment("x", 3, "c"),
+			want: map[string][]string{
+				"Host": {"scalar values differ"},
+				"Config": {
+					"struct.Limits [cpu] struct.Min scalar values differ",
+					"struct.Tags [1] scalar values differ",
+				},
+			},
+		},
+		{
+			name: "one field",
+			a1:   deployment("h", 1, "b"),
+			a2:   deployment("h", 1, "c"),
+			want: map[string][]string{"Config": {"struct.Tags [1] scalar values differ"}},
+		},
+		{
+			name: "pointers",
+			a1:   &testDeployment{Host: "a"},
+			a2:   &testDeployment{Host: "b"},
+			want: map[string][]string{"Host": {"scalar values differ"}},
+		},
+		{
+			name: "shared pointers",
+			a1:   testShared{A: p, B: p},
+			a2:   testShared{A: q, B: q},
+			want: map[string][]string{
+				"A": {"struct.Max scalar values differ"},
+				"B": {"struct.Max scalar values differ"},
+			},
+		},
+		{
+			name: "slice",
+			a1:   []testDeployment{{Host: "a"}, {Host: "b"}},
+			a2:   []testDeployment{{Host: "a"}, {Host: "c"}},
+			want: map[string][]string{"[1]": {"struct.Host scalar values differ"}},
+		},
+		{
+			name: "top",
+			a1:   1,
+			a2:   2,
+			want: map[string][]string{"": {"scalar values differ"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareGrouped(tt.a1, tt.a2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareGrouped() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
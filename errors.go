@@ -0,0 +1,57 @@
+package deepequal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// asError returns the error held by v, if v implements error and can be
+// used to call Error.
+func asError(v reflect.Value) (error, bool) {
+	if !v.CanInterface() || !v.Type().Implements(errorType) {
+		return nil, false
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, false
+	}
+	err, ok := v.Interface().(error)
+	return err, ok && err != nil
+}
+
+// compareErrorChains compares errors level by level along the chains
+// unwrapped with errors.Unwrap, by their types and messages.
+func compareErrorChains(e1, e2 error) (bool, string) {
+	for depth := 0; ; depth++ {
+		if e1 == nil || e2 == nil {
+			if e1 == nil && e2 == nil {
+				return true, ""
+			}
+			return false, fmt.Sprintf("error chains differ at depth %d: one chain ends", depth)
+		}
+		if reflect.TypeOf(e1) != reflect.TypeOf(e2) {
+			return false, fmt.Sprintf("error chains differ at depth %d: types %T vs %T", depth, e1, e2)
+		}
+		if e1.Error() != e2.Error() {
+			return false, fmt.Sprintf("error chains differ at depth %d: %q != %q", depth, e1.Error(), e2.Error())
+		}
+		e1, e2 = errors.Unwrap(e1), errors.Unwrap(e2)
+	}
+}
+
+// compareErrors compares v1 and v2 as error chains if both are errors.
+// handled is false otherwise.
+func compareErrors(v1, v2 reflect.Value) (handled, equal bool, reason string) {
+	e1, ok := asError(v1)
+	if !ok {
+		return false, false, ""
+	}
+	e2, ok := asError(v2)
+	if !ok {
+		return false, false, ""
+	}
+	equal, reason = compareErrorChains(e1, e2)
+	return true, equal, reason
+}
@@ -0,0 +1,109 @@
+package deepequal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+type testResult struct {
+	Value int
+	Err   error
+}
+
+func TestCompareWithOptions_CompareErrorChain(t *testing.T) {
+	base := errors.New("base")
+	wrap := func(err error) error {
+		return fmt.Errorf("load: %w", fmt.Errorf("read: %w", err))
+	}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "equal chains",
+			a1:   testResult{Err: wrap(errors.New("base"))},
+			a2:   testResult{Err: wrap(errors.New("base"))},
+			opts: Options{CompareErrorChain: true},
+			want: true,
+		},
+		{
+			name: "same base",
+			a1:   testResult{Err: wrap(base)},
+			a2:   testResult{Err: wrap(base)},
+			opts: Options{CompareErrorChain: true},
+			want: true,
+		},
+		{
+			name: "nil errors",
+			a1:   testResult{Value: 1},
+			a2:   testResult{Value: 1},
+			opts: Options{CompareErrorChain: true},
+			want: true,
+		},
+		{
+			name: "top level",
+			a1:   &os.PathError{Op: "open", Path: "a", Err: io.EOF},
+			a2:   &os.PathError{Op: "open", Path: "a", Err: io.EOF},
+			opts: Options{CompareErrorChain: true},
+			want: true,
+		},
+		{
+			name:       "top level differ",
+			a1:         &os.PathError{Op: "open", Path: "a", Err: io.EOF},
+			a2:         &os.PathError{Op: "open", Path: "a", Err: io.ErrUnexpectedEOF},
+			opts:       Options{CompareErrorChain: true},
+			want:       false,
+			wantReason: `error chains differ at depth 0: "open a: EOF" != "open a: unexpected EOF"`,
+		},
+		{
+			name:       "message differ at depth",
+			a1:         testResult{Err: wrap(errors.New("base"))},
+			a2:         testResult{Err: fmt.Errorf("load: %w", fmt.Errorf("read: %w", errors.New("other")))},
+			opts:       Options{CompareErrorChain: true},
+			want:       false,
+			wantReason: `struct.Err error chains differ at depth 0: "load: read: base" != "load: read: other"`,
+		},
+		{
+			name:       "type differ at depth",
+			a1:         testResult{Err: fmt.Errorf("load: %w", errors.New("base"))},
+			a2:         testResult{Err: fmt.Errorf("load: %w", testChainEnd{msg: "base"})},
+			opts:       Options{CompareErrorChain: true},
+			want:       false,
+			wantReason: "struct.Err error chains differ at depth 1: types *errors.errorString vs deepequal.testChainEnd",
+		},
+		{
+			name:       "wrapped and not",
+			a1:         testResult{Err: fmt.Errorf("load: %w", errors.New("base"))},
+			a2:         testResult{Err: fmt.Errorf("load: %v", errors.New("base"))},
+			opts:       Options{CompareErrorChain: true},
+			want:       false,
+			wantReason: "struct.Err error chains differ at depth 0: types *fmt.wrapError vs *errors.errorString",
+		},
+		{
+			name:       "chain ends",
+			a1:         testResult{Err: &os.PathError{Op: "open", Path: "a", Err: testChainEnd{}}},
+			a2:         testResult{Err: &os.PathError{Op: "open", Path: "a", Err: testChainEnd{next: io.EOF}}},
+			opts:       Options{CompareErrorChain: true},
+			want:       false,
+			wantReason: "struct.Err error chains differ at depth 2: one chain ends",
+		},
+		{
+			name:       "one nil",
+			a1:         testResult{Err: io.EOF},
+			a2:         testResult{},
+			opts:       Options{CompareErrorChain: true},
+			want:       false,
+			wantReason: "struct.Err both interfaces must be nil",
+		},
+	})
+}
+
+// testChainEnd has the same message whatever it wraps.
+type testChainEnd struct {
+	msg  string
+	next error
+}
+
+func (e testChainEnd) Error() string { return e.msg }
+
+func (e testChainEnd) Unwrap() error { return e.next }
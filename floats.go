@@ -0,0 +1,128 @@
+package deepequal
+
+import (
+	"math"
+	"reflect"
+)
+
+// FloatOpts adjusts the comparison of float values.
+type FloatOpts struct {
+	// Tolerance is the maximum absolute difference of values which are
+	// still equal. It doesn't apply to infinities, which only equal
+	// infinities of the same sign.
+	Tolerance float64
+	// NaNUnequal makes NaN unequal to any value, NaN included, as with ==.
+	// By default NaN equals NaN.
+	NaNUnequal bool
+	// Decimals rounds values to that many decimal places before comparing
+	// them (with math.Round, so 1.005 and 1.0049 are the same to 2
+	// decimals). Zero disables rounding.
+	Decimals int
+	// PercentTolerance is the maximum difference of values which are still
+	// equal in percent of the expected (first) value: 0.1 allows 0.1%. For
+	// an expected zero, only Tolerance applies, so set both to allow noise
+	// around zero.
+	PercentTolerance float64
+	// DistinguishSignedZero makes +0.0 and -0.0 unequal: 'signed zero
+	// mismatch'. By default they are equal, as with ==.
+	DistinguishSignedZero bool
+	// MaxULP is the maximum distance in units in the last place (the
+	// number of representable values between them) of values which are
+	// still equal, computed for the precision of the compared type. +0.0
+	// and -0.0 are at distance zero, NaN and infinities aren't affected.
+	MaxULP int
+}
+
+// floatOpts returns the float options for the type t.
+func (c *comparer) floatOpts(t reflect.Type) FloatOpts {
+	if opts, ok := c.opts.FloatTypes[t]; ok {
+		return opts
+	}
+	return c.opts.Float
+}
+
+// floatEqual compares float values of the same type.
+func (c *comparer) floatEqual(v1, v2 reflect.Value) (bool, string) {
+	if isAnyFloat(v1) {
+		return true, ""
+	}
+	opts := c.floatOpts(v1.Type())
+	f1, f2 := v1.Float(), v2.Float()
+	if math.IsNaN(f1) || math.IsNaN(f2) {
+		if !opts.NaNUnequal && math.IsNaN(f1) && math.IsNaN(f2) {
+			return true, ""
+		}
+		return false, c.scalarReason(v1, v2)
+	}
+	if opts.DistinguishSignedZero && f1 == 0 && f2 == 0 && math.Signbit(f1) != math.Signbit(f2) {
+		return false, "signed zero mismatch"
+	}
+	if opts.Decimals > 0 {
+		p := math.Pow(10, float64(opts.Decimals))
+		f1, f2 = roundDecimals(f1, p), roundDecimals(f2, p)
+	}
+	if f1 == f2 {
+		return true, ""
+	}
+	// equal infinities are handled above, Inf-Inf is NaN
+	if math.IsInf(f1, 0) || math.IsInf(f2, 0) {
+		return false, c.scalarReason(v1, v2)
+	}
+	if opts.Tolerance > 0 && math.Abs(f1-f2) <= opts.Tolerance {
+		return true, ""
+	}
+	if opts.PercentTolerance > 0 && math.Abs(f1-f2) <= opts.PercentTolerance/100*math.Abs(f1) {
+		return true, ""
+	}
+	if opts.MaxULP > 0 && ulpDistance(f1, f2, v1.Kind()) <= uint64(opts.MaxULP) {
+		return true, ""
+	}
+	return false, c.scalarReason(v1, v2)
+}
+
+// roundDecimals rounds f to the decimal places of p, a power of ten. Values
+// too large to scale by p (f*p beyond 2^53 or infinite) have no fraction
+// left at that precision and are kept as they are.
+func roundDecimals(f, p float64) float64 {
+	scaled := f * p
+	if math.IsInf(scaled, 0) || math.Abs(scaled) >= 1<<53 {
+		return f
+	}
+	return math.Round(scaled) / p
+}
+
+// ulpDistance returns the number of representable values of the float
+// kind k between the finite values f1 and f2.
+func ulpDistance(f1, f2 float64, k reflect.Kind) uint64 {
+	var i1, i2 int64
+	if k == reflect.Float32 {
+		i1, i2 = int64(orderedBits32(float32(f1))), int64(orderedBits32(float32(f2)))
+	} else {
+		i1, i2 = orderedBits64(f1), orderedBits64(f2)
+	}
+	if i1 > i2 {
+		i1, i2 = i2, i1
+	}
+	// the difference fits in uint64, but not always in int64
+	return uint64(i2) - uint64(i1)
+}
+
+// orderedBits64 maps the bits of f to integers ordered like floats, with
+// both zeros at 0, so adjacent floats are adjacent integers.
+func orderedBits64(f float64) int64 {
+	i := int64(math.Float64bits(f))
+	if i < 0 {
+		// sign and magnitude to two's complement
+		i = math.MinInt64 - i
+	}
+	return i
+}
+
+// orderedBits32 is orderedBits64 for float32.
+func orderedBits32(f float32) int32 {
+	i := int32(math.Float32bits(f))
+	if i < 0 {
+		i = math.MinInt32 - i
+	}
+	return i
+}
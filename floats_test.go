@@ -0,0 +1,415 @@
+package deepequal
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+type testCelsius float64
+
+type testMeters float32
+
+type testMeasure struct {
+	Temp   testCelsius
+	Length testMeters
+	Ratio  float64
+}
+
+func TestCompareWithOptions_Float(t *testing.T) {
+	perType := map[reflect.Type]FloatOpts{
+		reflect.TypeOf(testCelsius(0)): {Tolerance: 0.5},
+		reflect.TypeOf(testMeters(0)):  {Tolerance: 0.001, NaNUnequal: true},
+	}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "global tolerance",
+			a1:   []float64{1, 2},
+			a2:   []float64{1.05, 1.95},
+			opts: Options{Float: FloatOpts{Tolerance: 0.1}},
+			want: true,
+		},
+		{
+			name:       "global tolerance exceeded",
+			a1:         []float64{1, 2},
+			a2:         []float64{1.05, 1.8},
+			opts:       Options{Float: FloatOpts{Tolerance: 0.1}},
+			want:       false,
+			wantReason: "[1] scalar values differ",
+		},
+		{
+			name:       "global NaN unequal",
+			a1:         math.NaN(),
+			a2:         math.NaN(),
+			opts:       Options{Float: FloatOpts{NaNUnequal: true}},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name: "per type tolerance",
+			a1:   testMeasure{Temp: 20, Length: 1, Ratio: 0.5},
+			a2:   testMeasure{Temp: 20.4, Length: 1.0005, Ratio: 0.5},
+			opts: Options{FloatTypes: perType},
+			want: true,
+		},
+		{
+			name:       "per type tolerance exceeded",
+			a1:         testMeasure{Temp: 20, Length: 1, Ratio: 0.5},
+			a2:         testMeasure{Temp: 20.4, Length: 1.01, Ratio: 0.5},
+			opts:       Options{FloatTypes: perType},
+			want:       false,
+			wantReason: "struct.Length scalar values differ",
+		},
+		{
+			name:       "plain float64 not affected by per type",
+			a1:         testMeasure{Temp: 20, Length: 1, Ratio: 0.5},
+			a2:         testMeasure{Temp: 20, Length: 1, Ratio: 0.51},
+			opts:       Options{FloatTypes: perType},
+			want:       false,
+			wantReason: "struct.Ratio scalar values differ",
+		},
+		{
+			name:       "per type overrides global",
+			a1:         testMeasure{Temp: 20, Length: 1, Ratio: 0.5},
+			a2:         testMeasure{Temp: 20, Length: 1.1, Ratio: 0.51},
+			opts:       Options{Float: FloatOpts{Tolerance: 0.2}, FloatTypes: perType},
+			want:       false,
+			wantReason: "struct.Length scalar values differ",
+		},
+		{
+			name: "per type NaN",
+			a1:   testMeasure{Temp: testCelsius(math.NaN())},
+			a2:   testMeasure{Temp: testCelsius(math.NaN())},
+			opts: Options{FloatTypes: perType},
+			want: true,
+		},
+		{
+			name:       "per type NaN unequal",
+			a1:         testMeasure{Length: testMeters(math.NaN())},
+			a2:         testMeasure{Length: testMeters(math.NaN())},
+			opts:       Options{FloatTypes: perType},
+			want:       false,
+			wantReason: "struct.Length scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_FloatDecimals(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "same to 2 decimals",
+			a1:   1.005,
+			a2:   1.0049,
+			opts: Options{Float: FloatOpts{Decimals: 2}},
+			want: true,
+		},
+		{
+			name:       "different at 4 decimals",
+			a1:         1.005,
+			a2:         1.0049,
+			opts:       Options{Float: FloatOpts{Decimals: 4}},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name: "nested",
+			a1:   map[string][]float32{"a": {0.333, 2.5}},
+			a2:   map[string][]float32{"a": {0.3329, 2.501}},
+			opts: Options{Float: FloatOpts{Decimals: 2}},
+			want: true,
+		},
+		{
+			name:       "rounded apart",
+			a1:         []float64{1.006},
+			a2:         []float64{1.004},
+			opts:       Options{Float: FloatOpts{Decimals: 2}},
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+		{
+			name: "per type",
+			a1:   testMeasure{Temp: 20.01, Ratio: 0.5},
+			a2:   testMeasure{Temp: 19.99, Ratio: 0.5},
+			opts: Options{FloatTypes: map[reflect.Type]FloatOpts{reflect.TypeOf(testCelsius(0)): {Decimals: 1}}},
+			want: true,
+		},
+		{
+			name: "infinity",
+			a1:   math.Inf(1),
+			a2:   math.Inf(1),
+			opts: Options{Float: FloatOpts{Decimals: 2}},
+			want: true,
+		},
+		{
+			name:       "large values",
+			a1:         1e305,
+			a2:         2e305,
+			opts:       Options{Float: FloatOpts{Decimals: 10}},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name:       "large negative values",
+			a1:         []float64{-1e300},
+			a2:         []float64{-1.0000000001e300},
+			opts:       Options{Float: FloatOpts{Decimals: 2}},
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+		{
+			name: "large equal values",
+			a1:   1e20,
+			a2:   1e20,
+			opts: Options{Float: FloatOpts{Decimals: 2}},
+			want: true,
+		},
+		{
+			name:       "large values beyond 2^53 scaled",
+			a1:         1e16,
+			a2:         1e16 + 2,
+			opts:       Options{Float: FloatOpts{Decimals: 2}},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name:       "disabled",
+			a1:         1.005,
+			a2:         1.0049,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_DistinguishSignedZero(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	runOptionsTests(t, []optionsTest{
+		{
+			name:       "signed zeros",
+			a1:         0.0,
+			a2:         negZero,
+			opts:       Options{Float: FloatOpts{DistinguishSignedZero: true}},
+			want:       false,
+			wantReason: "signed zero mismatch",
+		},
+		{
+			name: "same sign",
+			a1:   []float64{negZero, 0},
+			a2:   []float64{negZero, 0},
+			opts: Options{Float: FloatOpts{DistinguishSignedZero: true}},
+			want: true,
+		},
+		{
+			name:       "within tolerance",
+			a1:         []float32{float32(negZero)},
+			a2:         []float32{0},
+			opts:       Options{Float: FloatOpts{DistinguishSignedZero: true, Tolerance: 0.1}},
+			want:       false,
+			wantReason: "[0] signed zero mismatch",
+		},
+		{
+			name: "default",
+			a1:   0.0,
+			a2:   negZero,
+			want: true,
+		},
+	})
+}
+
+func TestCompareWithOptions_FloatInfTolerance(t *testing.T) {
+	inf, negInf := math.Inf(1), math.Inf(-1)
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "same sign",
+			a1:   []float64{inf, negInf},
+			a2:   []float64{inf, negInf},
+			opts: Options{Float: FloatOpts{Tolerance: 0.1}},
+			want: true,
+		},
+		{
+			name:       "opposite signs",
+			a1:         inf,
+			a2:         negInf,
+			opts:       Options{Float: FloatOpts{Tolerance: 0.1}},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name:       "finite",
+			a1:         []float32{float32(inf)},
+			a2:         []float32{math.MaxFloat32},
+			opts:       Options{Float: FloatOpts{Tolerance: 0.1}},
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+		{
+			name:       "infinite tolerance",
+			a1:         1.0,
+			a2:         inf,
+			opts:       Options{Float: FloatOpts{Tolerance: inf}},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name: "finite with infinite tolerance",
+			a1:   1.0,
+			a2:   1e300,
+			opts: Options{Float: FloatOpts{Tolerance: inf}},
+			want: true,
+		},
+		{
+			name:       "rounded",
+			a1:         inf,
+			a2:         1.0,
+			opts:       Options{Float: FloatOpts{Tolerance: 0.1, Decimals: 2}},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_FloatPercentTolerance(t *testing.T) {
+	pct := Options{Float: FloatOpts{PercentTolerance: 0.1}}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "small values",
+			a1:   0.001,
+			a2:   0.0010009,
+			opts: pct,
+			want: true,
+		},
+		{
+			name: "large values",
+			a1:   []float64{1e9, -1e9},
+			a2:   []float64{1e9 + 999999, -1e9 - 999999},
+			opts: pct,
+			want: true,
+		},
+		{
+			name:       "over the percentage",
+			a1:         1000.0,
+			a2:         1001.5,
+			opts:       pct,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name:       "relative to expected",
+			a1:         []float32{1},
+			a2:         []float32{1000},
+			opts:       Options{Float: FloatOpts{PercentTolerance: 99.95}},
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+		{
+			name:       "expected zero",
+			a1:         0.0,
+			a2:         1e-12,
+			opts:       pct,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name: "expected zero with tolerance",
+			a1:   0.0,
+			a2:   1e-12,
+			opts: Options{Float: FloatOpts{PercentTolerance: 0.1, Tolerance: 1e-9}},
+			want: true,
+		},
+	})
+}
+
+func TestCompareWithOptions_FloatMaxULP(t *testing.T) {
+	ulp2 := Options{Float: FloatOpts{MaxULP: 2}}
+	next := func(f float64, n int) float64 {
+		for i := 0; i < n; i++ {
+			f = math.Nextafter(f, math.Inf(1))
+		}
+		return f
+	}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "within",
+			a1:   1.0,
+			a2:   next(1, 2),
+			opts: ulp2,
+			want: true,
+		},
+		{
+			name:       "beyond",
+			a1:         1.0,
+			a2:         next(1, 3),
+			opts:       ulp2,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name: "across a power of two",
+			a1:   math.Nextafter(2, 0),
+			a2:   next(2, 1),
+			opts: ulp2,
+			want: true,
+		},
+		{
+			name: "across zero",
+			a1:   -math.SmallestNonzeroFloat64,
+			a2:   math.SmallestNonzeroFloat64,
+			opts: ulp2,
+			want: true,
+		},
+		{
+			name:       "across zero beyond",
+			a1:         -math.SmallestNonzeroFloat64,
+			a2:         next(0, 2),
+			opts:       ulp2,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name:       "opposite signs",
+			a1:         -1.0,
+			a2:         1.0,
+			opts:       Options{Float: FloatOpts{MaxULP: math.MaxInt32}},
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name:       "max and infinity",
+			a1:         math.MaxFloat64,
+			a2:         math.Inf(1),
+			opts:       ulp2,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name: "signed zeros",
+			a1:   0.0,
+			a2:   math.Copysign(0, -1),
+			opts: ulp2,
+			want: true,
+		},
+		{
+			name:       "NaN",
+			a1:         math.NaN(),
+			a2:         1.0,
+			opts:       ulp2,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name: "float32 precision",
+			a1:   testMeasure{Length: 1},
+			a2:   testMeasure{Length: testMeters(math.Nextafter32(math.Nextafter32(1, 2), 2))},
+			opts: ulp2,
+			want: true,
+		},
+		{
+			name:       "float32 beyond",
+			a1:         []float32{1},
+			a2:         []float32{1.000001},
+			opts:       Options{Float: FloatOpts{MaxULP: 1}},
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+	})
+}
@@ -0,0 +1,78 @@
+package deepequal
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"sort"
+	"strings"
+)
+
+// updateGolden tells if the golden files should be rewritten: the test
+// binary defines a boolean -update flag and it's set.
+func updateGolden() bool {
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	g, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	update, _ := g.Get().(bool)
+	return update
+}
+
+// TestingT is the part of testing.TB used by CompareGolden, so the package
+// doesn't import testing: *testing.T and *testing.B implement it.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// CompareGolden compares got with the golden file at goldenPath, which
+// holds the expected value encoded as indented JSON. The JSON documents
+// are compared like with CompareJSON and every difference is reported
+// with t.Errorf, starting with its JSON pointer, sorted. If the test binary
+// defines a boolean -update flag (the package doesn't) and it's set, the
+// golden file is written from got instead:
+//
+//	var update = flag.Bool("update", false, "update golden files")
+func CompareGolden(t TestingT, got interface{}, goldenPath string) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("encode %s: %v", goldenPath, err)
+		return
+	}
+	data = append(data, '\n')
+	if updateGolden() {
+		if err = os.WriteFile(goldenPath, data, 0o644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+		return
+	}
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+		return
+	}
+	var want, have interface{}
+	if err = json.Unmarshal(golden, &want); err != nil {
+		t.Fatalf("decode golden file %s: %v", goldenPath, err)
+		return
+	}
+	if err = json.Unmarshal(data, &have); err != nil {
+		t.Fatalf("decode %s: %v", goldenPath, err)
+		return
+	}
+	var diffs []string
+	compareAll(want, have, Options{}, func(d Difference) {
+		diffs = append(diffs, strings.TrimPrefix(jsonPointer(d.Path)+" "+d.Reason, " "))
+	})
+	if len(diffs) > 0 {
+		sort.Strings(diffs)
+		t.Errorf("%s differs from the golden file (run with -update to rewrite it):\n%s", goldenPath, strings.Join(diffs, "\n"))
+	}
+}
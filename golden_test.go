@@ -0,0 +1,94 @@
+package deepequal
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// testTB records the failures reported to it.
+type testTB struct {
+	errors []string
+}
+
+var _ TestingT = (testing.TB)(nil)
+
+func (t *testTB) Helper() {}
+
+func (t *testTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *testTB) Fatalf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCompareGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	golden := "{\n  \"Name\": \"a\",\n  \"S\": [1, 2],\n  \"M\": {\"1\": \"x\"}\n}\n"
+	if err := os.WriteFile(path, []byte(golden), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		got        interface{}
+		wantErrors []string
+	}{
+		{
+			name: "equal",
+			got:  testStruct{Name: "a", S: []int{1, 2}, M: map[int]string{1: "x"}},
+		},
+		{
+			name: "differ",
+			got:  testStruct{Name: "b", S: []int{1, 3}, M: map[int]string{1: "x"}},
+			wantErrors: []string{
+				path + " differs from the golden file (run with -update to rewrite it):\n" +
+					"/Name scalar values differ\n/S/1 scalar values differ",
+			},
+		},
+		{
+			name:       "not encodable",
+			got:        func() {},
+			wantErrors: []string{"encode " + path + ": json: unsupported type: func()"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &testTB{}
+			CompareGolden(tb, tt.got, path)
+			if ok, reason := Compare(tb.errors, tt.wantErrors); !ok {
+				t.Errorf("CompareGolden() errors = %q, want %q: %s", tb.errors, tt.wantErrors, reason)
+			}
+		})
+	}
+}
+
+func TestCompareGolden_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.json")
+	got := testStruct{Name: "a", S: []int{1}}
+
+	tb := &testTB{}
+	CompareGolden(tb, got, path)
+	if len(tb.errors) != 1 {
+		t.Fatalf("CompareGolden() errors = %q, want missing golden file", tb.errors)
+	}
+
+	old := *update
+	*update = true
+	tb = &testTB{}
+	CompareGolden(tb, got, path)
+	*update = old
+	if len(tb.errors) != 0 {
+		t.Fatalf("CompareGolden() with -update errors = %q", tb.errors)
+	}
+
+	tb = &testTB{}
+	CompareGolden(tb, got, path)
+	if len(tb.errors) != 0 {
+		t.Errorf("CompareGolden() after update errors = %q", tb.errors)
+	}
+}
@@ -0,0 +1,105 @@
+package deepequal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// goExpr returns the Go expression for the value at path in root, which is
+// named x, along with the value. ok is false if the value isn't
+// addressable: it's held by an interface, or it's a part of a map value.
+func goExpr(root reflect.Value, path []PathElem) (expr string, v reflect.Value, ok bool) {
+	expr, v = "x", root
+	for i, e := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return "", v, false
+			}
+			if e.Kind != PathField {
+				// fields are selected through pointers implicitly
+				expr = "(*" + expr + ")"
+			}
+			v = v.Elem()
+		}
+		last := i == len(path)-1
+		switch {
+		case e.Kind == PathField && v.Kind() == reflect.Struct:
+			expr += "." + e.Name
+			v = v.FieldByName(e.Name)
+		case e.Kind == PathIndex && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+			expr += fmt.Sprintf("[%d]", e.Index)
+			if e.Index < v.Len() {
+				v = v.Index(e.Index)
+			} else if !last {
+				return "", v, false
+			}
+		case e.Kind == PathKey && v.Kind() == reflect.Map && last:
+			expr += fmt.Sprintf("[%#v]", e.Key)
+		default:
+			return "", v, false
+		}
+	}
+	return expr, v, true
+}
+
+// goValue formats value as a Go expression.
+func goValue(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%#v", value)
+}
+
+// goStatement returns the Go statement performing op on x, a1 before the
+// operations.
+func goStatement(root reflect.Value, op PatchOp) (string, bool) {
+	if op.Op == PatchSet {
+		expr, _, ok := goExpr(root, op.Path)
+		if !ok {
+			return "", false
+		}
+		return expr + " = " + goValue(op.Value), true
+	}
+
+	last := op.Path[len(op.Path)-1]
+	container, v, ok := goExpr(root, op.Path[:len(op.Path)-1])
+	if !ok || v.Kind() == reflect.Interface {
+		return "", false
+	}
+	for v.Kind() == reflect.Ptr {
+		container, v = "(*"+container+")", v.Elem()
+	}
+	switch {
+	case last.Kind == PathKey && op.Op == PatchDelete:
+		return fmt.Sprintf("delete(%s, %#v)", container, last.Key), true
+	case last.Kind == PathKey:
+		return fmt.Sprintf("%s[%#v] = %s", container, last.Key, goValue(op.Value)), true
+	case last.Kind == PathIndex && op.Op == PatchDelete:
+		// ComparePatch deletes the last element
+		return fmt.Sprintf("%s = %s[:%d]", container, container, last.Index), true
+	case last.Kind == PathIndex:
+		// ComparePatch inserts at the end
+		return fmt.Sprintf("%s = append(%s, %s)", container, container, goValue(op.Value)), true
+	}
+	return "", false
+}
+
+// CompareGoPatch returns the Go statements turning a1, named x, into a2,
+// one per line, like 'x.S[2] = 4' or 'delete(x.M, "k")', made from the
+// operations of ComparePatch. Changes which can't be assigned (inside map
+// values or interfaces) are commented out, like '// struct.M [k]
+// struct.N: not addressable'. It's empty for equal values.
+func CompareGoPatch(a1, a2 interface{}) string {
+	root := reflect.ValueOf(a1)
+	var b strings.Builder
+	for _, op := range ComparePatch(a1, a2) {
+		if stmt, ok := goStatement(root, op); ok {
+			b.WriteString(stmt)
+		} else {
+			fmt.Fprintf(&b, "// %s: not addressable", formatPath(op.Path))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package deepequal
+
+import (
+	"testing"
+)
+
+func TestCompareGoPatch(t *testing.T) {
+	tests := []struct {
+		name string
+		a1   interface{}
+		a2   interface{}
+		want string
+	}{
+		{
+			name: "equal",
+			a1:   testStruct{Name: "a", S: []int{1}},
+			a2:   testStruct{Name: "a", S: []int{1}},
+			want: "",
+		},
+		{
+			name: "fields and elements",
+			a1:   testStruct{Name: "a", S: []int{1, 2, 3}},
+			a2:   testStruct{Name: "b", S: []int{1, 2, 4, 5}},
+			want: "x.Name = \"b\"\n" +
+				"x.S[2] = 4\n" +
+				"x.S = append(x.S, 5)\n",
+		},
+		{
+			name: "map keys",
+			a1:   &testStruct{M: map[int]string{1: "a", 2: "b"}},
+			a2:   &testStruct{M: map[int]string{1: "x", 3: "c"}},
+			want: "x.M[1] = \"x\"\n" +
+				"delete(x.M, 2)\n" +
+				"x.M[3] = \"c\"\n",
+		},
+		{
+			name: "shortened slice",
+			a1:   []int{1, 2, 3},
+			a2:   []int{1},
+			want: "x = x[:2]\n" +
+				"x = x[:1]\n",
+		},
+		{
+			name: "pointer to slice",
+			a1:   &[]string{"a"},
+			a2:   &[]string{"b", "c"},
+			want: "(*x)[0] = \"b\"\n" +
+				"(*x) = append((*x), \"c\")\n",
+		},
+		{
+			name: "nested",
+			a1:   testInventory{Items: []testItem{{ID: 1}}, Matrix: [2][]int{{1}, nil}},
+			a2:   testInventory{Items: []testItem{{ID: 2, Tags: []string{"t"}}}, Matrix: [2][]int{{2}, nil}},
+			want: "x.Items[0].ID = 2\n" +
+				"x.Items[0].Tags = []string{\"t\"}\n" +
+				"x.Matrix[0][0] = 2\n",
+		},
+		{
+			name: "not addressable",
+			a1:   map[string]testItem{"a": {ID: 1}},
+			a2:   map[string]testItem{"a": {ID: 2}},
+			want: "// [a] struct.ID: not addressable\n",
+		},
+		{
+			name: "whole value",
+			a1:   1,
+			a2:   2,
+			want: "x = 2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareGoPatch(tt.a1, tt.a2); got != tt.want {
+				t.Errorf("CompareGoPatch() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package deepequal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CompareHunks compares slices (or arrays) a1 and a2 element by element,
+// like Compare, and renders only the differing elements with context
+// equal elements around them, in hunks like a unified diff:
+//
+//	@@ -1,5 +1,5 @@
+//	  [1] 1
+//	  [2] 2
+//	- [3] 3
+//	+ [3] 4
+//	  [4] 4
+//	  [5] 5
+//
+// Hunk headers hold the first index and the number of elements of each
+// side. Values other than slices or arrays of the same type, and a nil
+// slice with a non-nil one, are rendered as the reason returned by
+// Compare. It's empty for equal values.
+func CompareHunks(a1, a2 interface{}, context int) (bool, string) {
+	v1, v2 := reflect.ValueOf(a1), reflect.ValueOf(a2)
+	isSlice := func(v reflect.Value) bool {
+		return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	}
+	if a1 == nil || a2 == nil || v1.Type() != v2.Type() || !isSlice(v1) {
+		return Compare(a1, a2)
+	}
+	if v1.Kind() == reflect.Slice && v1.IsNil() != v2.IsNil() {
+		// no element differs, but a nil slice differs from an empty one
+		return Compare(a1, a2)
+	}
+	if context < 0 {
+		context = 0
+	}
+	differs := sliceMask(v1, v2)
+	n := len(differs)
+	var diffs []int
+	for i, differ := range differs {
+		if differ {
+			diffs = append(diffs, i)
+		}
+	}
+	if len(diffs) == 0 {
+		return true, ""
+	}
+
+	var b strings.Builder
+	for k := 0; k < len(diffs); {
+		lo := diffs[k] - context
+		if lo < 0 {
+			lo = 0
+		}
+		// join hunks which would touch
+		hi := diffs[k] + context + 1
+		for k++; k < len(diffs) && diffs[k]-context <= hi; k++ {
+			hi = diffs[k] + context + 1
+		}
+		if hi > n {
+			hi = n
+		}
+		writeHunk(&b, v1, v2, lo, hi, differs)
+	}
+	return false, b.String()
+}
+
+// sideLen returns the number of elements of a side with length n in the
+// range of indices [lo, hi).
+func sideLen(lo, hi, n int) int {
+	if hi > n {
+		hi = n
+	}
+	if hi < lo {
+		return 0
+	}
+	return hi - lo
+}
+
+func writeHunk(b *strings.Builder, v1, v2 reflect.Value, lo, hi int, differs []bool) {
+	n1, n2 := v1.Len(), v2.Len()
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", lo, sideLen(lo, hi, n1), lo, sideLen(lo, hi, n2))
+	for i := lo; i < hi; i++ {
+		if !differs[i] {
+			fmt.Fprintf(b, "  [%d] %+v\n", i, valueInterface(v1.Index(i)))
+			continue
+		}
+		if i < n1 {
+			fmt.Fprintf(b, "- [%d] %+v\n", i, valueInterface(v1.Index(i)))
+		}
+		if i < n2 {
+			fmt.Fprintf(b, "+ [%d] %+v\n", i, valueInterface(v2.Index(i)))
+		}
+	}
+}
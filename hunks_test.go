@@ -0,0 +1,125 @@
+package deepequal
+
+import (
+	"testing"
+)
+
+func TestCompareHunks(t *testing.T) {
+	long := func(changes map[int]int) []int {
+		s := make([]int, 60)
+		for i := range s {
+			s[i] = i
+		}
+		for i, v := range changes {
+			s[i] = v
+		}
+		return s
+	}
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		context    int
+		want       bool
+		wantReason string
+	}{
+		{
+			name:    "equal",
+			a1:      long(nil),
+			a2:      long(nil),
+			context: 2,
+			want:    true,
+		},
+		{
+			name:    "two hunks",
+			a1:      long(nil),
+			a2:      long(map[int]int{3: -3, 50: -50}),
+			context: 2,
+			want:    false,
+			wantReason: "@@ -1,5 +1,5 @@\n" +
+				"  [1] 1\n" +
+				"  [2] 2\n" +
+				"- [3] 3\n" +
+				"+ [3] -3\n" +
+				"  [4] 4\n" +
+				"  [5] 5\n" +
+				"@@ -48,5 +48,5 @@\n" +
+				"  [48] 48\n" +
+				"  [49] 49\n" +
+				"- [50] 50\n" +
+				"+ [50] -50\n" +
+				"  [51] 51\n" +
+				"  [52] 52\n",
+		},
+		{
+			name:    "joined hunks",
+			a1:      []string{"a", "b", "c", "d", "e", "f"},
+			a2:      []string{"x", "b", "c", "y", "e", "f"},
+			context: 1,
+			want:    false,
+			wantReason: "@@ -0,5 +0,5 @@\n" +
+				"- [0] a\n" +
+				"+ [0] x\n" +
+				"  [1] b\n" +
+				"  [2] c\n" +
+				"- [3] d\n" +
+				"+ [3] y\n" +
+				"  [4] e\n",
+		},
+		{
+			name:    "different lengths",
+			a1:      []int{1, 2, 3},
+			a2:      []int{1, 2, 3, 4, 5},
+			context: 1,
+			want:    false,
+			wantReason: "@@ -2,1 +2,3 @@\n" +
+				"  [2] 3\n" +
+				"+ [3] 4\n" +
+				"+ [4] 5\n",
+		},
+		{
+			name:    "no context",
+			a1:      [3]int{1, 2, 3},
+			a2:      [3]int{1, 5, 3},
+			context: 0,
+			want:    false,
+			wantReason: "@@ -1,1 +1,1 @@\n" +
+				"- [1] 2\n" +
+				"+ [1] 5\n",
+		},
+		{
+			name:       "nil and empty",
+			a1:         []int(nil),
+			a2:         []int{},
+			context:    2,
+			want:       false,
+			wantReason: "one slice is nil, the other is not",
+		},
+		{
+			name:    "empty arrays",
+			a1:      [0]int{},
+			a2:      [0]int{},
+			context: 2,
+			want:    true,
+		},
+		{
+			name:       "not slices",
+			a1:         map[string]int{"a": 1},
+			a2:         map[string]int{"a": 2},
+			context:    2,
+			want:       false,
+			wantReason: "[a] scalar values differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareHunks(tt.a1, tt.a2, tt.context)
+			if got != tt.want {
+				t.Errorf("CompareHunks() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareHunks() got1 =\n%s\nwant\n%s", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package deepequal
+
+import (
+	"fmt"
+	"image"
+)
+
+// CompareImages compares images by bounds and then by the RGBA() values
+// of their pixels, whatever their color models, reporting the first
+// differing pixel in row order: 'pixel (1, 2) differs: rgba(...) != ...'.
+func CompareImages(a, b image.Image) (bool, string) {
+	return CompareImagesTolerance(a, b, 0)
+}
+
+// CompareImagesTolerance compares images like CompareImages, but the
+// channels of pixels (in the 0-0xffff range of RGBA()) may differ by up to
+// tolerance, for lossy formats.
+func CompareImagesTolerance(a, b image.Image, tolerance uint32) (bool, string) {
+	if a == nil || b == nil {
+		return bothOrNone(a == nil, b == nil, "one image is nil, the other is not")
+	}
+	if !a.Bounds().Eq(b.Bounds()) {
+		return false, fmt.Sprintf("bounds differ: %v != %v", a.Bounds(), b.Bounds())
+	}
+	r := a.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			r1, g1, b1, a1 := a.At(x, y).RGBA()
+			r2, g2, b2, a2 := b.At(x, y).RGBA()
+			if channelDiff(r1, r2) > tolerance || channelDiff(g1, g2) > tolerance ||
+				channelDiff(b1, b2) > tolerance || channelDiff(a1, a2) > tolerance {
+				return false, fmt.Sprintf("pixel (%d, %d) differs: rgba(%d, %d, %d, %d) != rgba(%d, %d, %d, %d)",
+					x, y, r1, g1, b1, a1, r2, g2, b2, a2)
+			}
+		}
+	}
+	return true, ""
+}
+
+func channelDiff(c1, c2 uint32) uint32 {
+	if c1 > c2 {
+		return c1 - c2
+	}
+	return c2 - c1
+}
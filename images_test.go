@@ -0,0 +1,94 @@
+package deepequal
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func testImage(w, h int, set func(img *image.RGBA)) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 10), G: uint8(y * 10), B: 100, A: 255})
+		}
+	}
+	if set != nil {
+		set(img)
+	}
+	return img
+}
+
+func TestCompareImages(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 2, 2))
+	gray.SetGray(1, 1, color.Gray{Y: 255})
+	rgbaGray := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	rgbaGray.Set(0, 0, color.Black)
+	rgbaGray.Set(1, 0, color.Black)
+	rgbaGray.Set(0, 1, color.Black)
+	rgbaGray.Set(1, 1, color.White)
+	tests := []struct {
+		name       string
+		a          image.Image
+		b          image.Image
+		tolerance  uint32
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "identical",
+			a:    testImage(4, 3, nil),
+			b:    testImage(4, 3, nil),
+			want: true,
+		},
+		{
+			name:       "one pixel",
+			a:          testImage(4, 3, nil),
+			b:          testImage(4, 3, func(img *image.RGBA) { img.Set(2, 1, color.RGBA{R: 20, G: 10, B: 101, A: 255}) }),
+			want:       false,
+			wantReason: "pixel (2, 1) differs: rgba(5140, 2570, 25700, 65535) != rgba(5140, 2570, 25957, 65535)",
+		},
+		{
+			name:      "within tolerance",
+			a:         testImage(4, 3, nil),
+			b:         testImage(4, 3, func(img *image.RGBA) { img.Set(2, 1, color.RGBA{R: 20, G: 10, B: 101, A: 255}) }),
+			tolerance: 0x101,
+			want:      true,
+		},
+		{
+			name:       "bounds",
+			a:          testImage(4, 3, nil),
+			b:          testImage(3, 4, nil),
+			want:       false,
+			wantReason: "bounds differ: (0,0)-(4,3) != (0,0)-(3,4)",
+		},
+		{
+			name: "color models",
+			a:    gray,
+			b:    rgbaGray,
+			want: true,
+		},
+		{
+			name:       "nil",
+			a:          testImage(1, 1, nil),
+			want:       false,
+			wantReason: "one image is nil, the other is not",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareImagesTolerance(tt.a, tt.b, tt.tolerance)
+			if got != tt.want {
+				t.Errorf("CompareImagesTolerance() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareImagesTolerance() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+			if tt.tolerance == 0 {
+				if got, gotReason = CompareImages(tt.a, tt.b); got != tt.want || gotReason != tt.wantReason {
+					t.Errorf("CompareImages() = %v, '%v'", got, gotReason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package deepequal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// firstDifference compares a1 and a2 and returns the first difference
+// found, if any.
+func firstDifference(a1, a2 interface{}, opts Options) (Difference, bool) {
+	c := newComparer(&opts)
+	c.trackFirst = true
+	if equal, _ := c.compareRoot(a1, a2); equal || c.first == nil {
+		return Difference{}, false
+	}
+	return *c.first, true
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPointer formats the path as a JSON pointer (RFC 6901).
+func jsonPointer(path []PathElem) string {
+	var sb strings.Builder
+	for _, e := range path {
+		sb.WriteByte('/')
+		switch e.Kind {
+		case PathIndex:
+			sb.WriteString(strconv.Itoa(e.Index))
+		case PathKey:
+			if s, ok := e.Key.(string); ok {
+				sb.WriteString(jsonPointerEscaper.Replace(s))
+			} else {
+				sb.WriteString(jsonPointerEscaper.Replace(e.String()))
+			}
+		default:
+			sb.WriteString(jsonPointerEscaper.Replace(e.Name))
+		}
+	}
+	return sb.String()
+}
+
+var jsonPathEscaper = strings.NewReplacer(
+	`\`, `\\`, ".", `\.`, "*", `\*`, "?", `\?`, "|", `\|`, "#", `\#`, "@", `\@`,
+)
+
+// jsonPath formats the path in the syntax of gjson and similar JSON path
+// libraries: the steps (field names, indexes and keys) joined with dots,
+// like 'S.2', with the special characters of names and keys escaped.
+func jsonPath(path []PathElem) string {
+	elems := make([]string, len(path))
+	for i, e := range path {
+		switch e.Kind {
+		case PathIndex:
+			elems[i] = strconv.Itoa(e.Index)
+		case PathKey:
+			if s, ok := e.Key.(string); ok {
+				elems[i] = jsonPathEscaper.Replace(s)
+			} else {
+				elems[i] = jsonPathEscaper.Replace(fmt.Sprintf("%+v", e.Key))
+			}
+		default:
+			elems[i] = jsonPathEscaper.Replace(e.Name)
+		}
+	}
+	return strings.Join(elems, ".")
+}
+
+// CompareJSON tests two JSON documents for semantic equality: they are
+// decoded into interface{}, so object keys order, whitespace and numbers
+// formatting don't matter (numbers are compared as float64). The reason
+// starts with the JSON pointer (RFC 6901) to the first difference.
+func CompareJSON(a, b string) (bool, string) {
+	var v1, v2 interface{}
+	if err := json.Unmarshal([]byte(a), &v1); err != nil {
+		return false, "invalid first JSON: " + err.Error()
+	}
+	if err := json.Unmarshal([]byte(b), &v2); err != nil {
+		return false, "invalid second JSON: " + err.Error()
+	}
+	d, differ := firstDifference(v1, v2, Options{})
+	if !differ {
+		return true, ""
+	}
+	if len(d.Path) == 0 {
+		return false, d.Reason
+	}
+	return false, jsonPointer(d.Path) + " " + d.Reason
+}
+
+// CanonicalHash returns the SHA-256 hex digest of the canonical JSON form
+// of v: v is encoded to JSON and decoded back into interface{} like with
+// CompareJSON, then encoded again with sorted object keys. Values equal
+// for CompareJSON have the same hash. Values which can't be encoded to
+// JSON (like functions or channels) give an error.
+func CanonicalHash(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var tree interface{}
+	if err = json.Unmarshal(data, &tree); err != nil {
+		return "", err
+	}
+	if data, err = json.Marshal(tree); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
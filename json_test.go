@@ -0,0 +1,114 @@
+package deepequal
+
+import "testing"
+
+func TestCompareJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		a          string
+		b          string
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "reordered keys and whitespace",
+			a:    `{"a": 1, "b": [1, 2], "c": {"d": null}}`,
+			b:    "{\n\t\"c\": {\"d\": null},\n\t\"b\": [1,2],\n\t\"a\": 1\n}",
+			want: true,
+		},
+		{
+			name: "numbers formatting",
+			a:    `{"a": 1, "b": 0.5}`,
+			b:    `{"a": 1.0, "b": 5e-1}`,
+			want: true,
+		},
+		{
+			name:       "value differ",
+			a:          `{"a": {"b": [1, 2, 3]}}`,
+			b:          `{"a": {"b": [1, 2, 4]}}`,
+			want:       false,
+			wantReason: "/a/b/2 scalar values differ",
+		},
+		{
+			name:       "type differ",
+			a:          `{"a": [{"x/y~z": 1}]}`,
+			b:          `{"a": [{"x/y~z": "1"}]}`,
+			want:       false,
+			wantReason: "/a/0/x~1y~0z values are of differing types: float64 vs string",
+		},
+		{
+			name:       "missing key",
+			a:          `{"a": 1}`,
+			b:          `{"b": 1}`,
+			want:       false,
+			wantReason: "/a invalid values are not equal",
+		},
+		{
+			name:       "array length",
+			a:          `{"a": [1]}`,
+			b:          `{"a": [1, 2]}`,
+			want:       false,
+			wantReason: "/a slices have different lengths",
+		},
+		{
+			name:       "top level",
+			a:          `1`,
+			b:          `2`,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name:       "invalid",
+			a:          `{`,
+			b:          `{}`,
+			want:       false,
+			wantReason: "invalid first JSON: unexpected end of JSON input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareJSON(tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("CompareJSON() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareJSON() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCanonicalHash(t *testing.T) {
+	type record struct {
+		B int            `json:"b"`
+		A map[string]int `json:"a"`
+	}
+	hash := func(v interface{}) string {
+		t.Helper()
+		h, err := CanonicalHash(v)
+		if err != nil {
+			t.Fatalf("CanonicalHash(%v) error = %v", v, err)
+		}
+		return h
+	}
+
+	h1 := hash(map[string]interface{}{"a": map[string]int{"x": 1, "y": 2}, "b": 1})
+	h2 := hash(map[string]interface{}{"b": 1.0, "a": map[string]int{"y": 2, "x": 1}})
+	h3 := hash(record{A: map[string]int{"y": 2, "x": 1}, B: 1})
+	if h1 != h2 || h1 != h3 {
+		t.Errorf("CanonicalHash() of equal values differ: %s, %s, %s", h1, h2, h3)
+	}
+	if len(h1) != 64 {
+		t.Errorf("CanonicalHash() = %s, want SHA-256 hex digest", h1)
+	}
+	if h4 := hash(record{A: map[string]int{"y": 2, "x": 1}, B: 2}); h4 == h1 {
+		t.Errorf("CanonicalHash() of different values are equal: %s", h4)
+	}
+
+	if _, err := CanonicalHash(func() {}); err == nil {
+		t.Error("CanonicalHash(func) error = nil")
+	}
+	if _, err := CanonicalHash(map[string]interface{}{"c": make(chan int)}); err == nil {
+		t.Error("CanonicalHash(chan) error = nil")
+	}
+}
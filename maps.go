@@ -0,0 +1,263 @@
+package deepequal
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EqualMap tests maps of comparable values for equality with ==, without
+// reflection on the keys and values. For values of basic types it gives the
+// same results and reasons as Compare, including NaN == NaN for float
+// values and a nil map not being equal to an empty one. Values of other
+// types are compared with == as a whole: a NaN held in a struct, an array
+// or an interface is not equal to itself, unlike with Compare, the reasons
+// name only the key, and interfaces holding values which aren't
+// comparable (like slices) panic.
+func EqualMap[K comparable, V comparable](a, b map[K]V) (bool, string) {
+	floatValues := isFloat(reflect.TypeOf((*V)(nil)).Elem().Kind())
+	if (a == nil) != (b == nil) {
+		return false, "one map is nil, one is not"
+	}
+	if len(a) != len(b) {
+		return false, "maps have different lengths"
+	}
+	for k, v1 := range a {
+		v2, ok := b[k]
+		if !ok {
+			return false, fmt.Sprintf("[%+v] invalid values are not equal", k)
+		}
+		// v != v is only true for NaN, for float values
+		if v1 != v2 && (!floatValues || v1 == v1 || v2 == v2) {
+			return false, fmt.Sprintf("[%+v] scalar values differ", k)
+		}
+	}
+	return true, ""
+}
+
+// mapElemEqual compares the values of a map entry.
+func (c *comparer) mapElemEqual(e1, e2 reflect.Value, depth int) (bool, string) {
+	if c.opts.LooseMapNumerics {
+		if handled, equal, reason := looseNumbers(e1, e2); handled {
+			return equal, reason
+		}
+	}
+	if c.opts.UnorderedMapValueSlices && e1.Kind() == reflect.Slice {
+		c.unorderedSlice = true
+		// the slice may be handled before reaching it, like by a hook
+		defer func() { c.unorderedSlice = false }()
+	}
+	return c.deepValueEqual(e1, e2, depth)
+}
+
+// mapKeys returns the keys of the map v, sorted with DeterministicMapOrder.
+func (c *comparer) mapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	if c.opts.DeterministicMapOrder {
+		sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+	}
+	return keys
+}
+
+// mapByPointerKeys compares maps of the same length with pointer keys,
+// matching the keys by the values they point to.
+func (c *comparer) mapByPointerKeys(v1, v2 reflect.Value, depth int) (bool, string) {
+	keys2 := v2.MapKeys()
+	matched := make([]bool, len(keys2))
+	result := true
+	for _, k1 := range c.mapKeys(v1) {
+		found := -1
+		for i, k2 := range keys2 {
+			if matched[i] {
+				continue
+			}
+			// failed attempts must not be remembered as visited
+			if equal, _ := c.probe().deepValueEqual(k1.Elem(), k2.Elem(), depth+1); equal {
+				found = i
+				break
+			}
+		}
+		elem := keyElem(k1.Elem())
+		var equal bool
+		var reason string
+		if found < 0 {
+			equal, reason = c.elemDiff(elem, v1.MapIndex(k1), reflect.Value{}, "key not found")
+		} else {
+			matched[found] = true
+			equal, reason = c.descend(elem, v1.MapIndex(k1), v2.MapIndex(keys2[found]), depth, c.mapElemEqual)
+		}
+		if !equal {
+			if !c.all {
+				return false, reason
+			}
+			result = false
+		}
+	}
+	return result, ""
+}
+
+// foldKeys indexes the string keys of the map v by their lower case form.
+// It fails with a reason if two keys are the same ignoring case.
+func foldKeys(v reflect.Value) (map[string]reflect.Value, string) {
+	keys := make(map[string]reflect.Value, v.Len())
+	for _, k := range v.MapKeys() {
+		folded := strings.ToLower(k.String())
+		if prev, ok := keys[folded]; ok {
+			k1, k2 := prev.String(), k.String()
+			if k1 > k2 {
+				k1, k2 = k2, k1
+			}
+			return nil, fmt.Sprintf("keys %q and %q are ambiguous ignoring case", k1, k2)
+		}
+		keys[folded] = k
+	}
+	return keys, ""
+}
+
+// mapCaseInsensitive compares maps of the same length with string keys,
+// matching the keys ignoring case.
+func (c *comparer) mapCaseInsensitive(v1, v2 reflect.Value, depth int) (bool, string) {
+	if _, reason := foldKeys(v1); reason != "" {
+		return false, "first map " + reason
+	}
+	keys2, reason := foldKeys(v2)
+	if reason != "" {
+		return false, "second map " + reason
+	}
+	result := true
+	for _, k1 := range c.mapKeys(v1) {
+		var equal bool
+		if k2, ok := keys2[strings.ToLower(k1.String())]; ok {
+			equal, reason = c.descend(keyElem(k1), v1.MapIndex(k1), v2.MapIndex(k2), depth, c.mapElemEqual)
+		} else {
+			equal, reason = c.elemDiff(keyElem(k1), v1.MapIndex(k1), reflect.Value{}, "key not found ignoring case")
+		}
+		if !equal {
+			if !c.all {
+				return false, reason
+			}
+			result = false
+		}
+	}
+	return result, ""
+}
+
+// mapSchema compares the map values set in v1 (the reference of
+// CompareSchema) with those of v2, ignoring the other keys of v2.
+func (c *comparer) mapSchema(v1, v2 reflect.Value, depth int) (bool, string) {
+	keys := v1.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+	result := true
+	for _, k := range keys {
+		e1, e2 := v1.MapIndex(k), v2.MapIndex(k)
+		if c.isEmpty(e1) {
+			continue
+		}
+		var equal bool
+		var reason string
+		if e2.IsValid() {
+			equal, reason = c.descend(keyElem(k), e1, e2, depth, c.mapElemEqual)
+		} else {
+			equal, reason = c.elemDiff(keyElem(k), e1, e2, "key missing in actual")
+		}
+		if !equal {
+			if !c.all {
+				return false, reason
+			}
+			result = false
+		}
+	}
+	return result, ""
+}
+
+// CompareMapDiff compares maps like Compare, but reports every differing
+// key instead of the first one, sorted by key: a reason for each value
+// which differs and '[KEY] key only in first map' (or second map) for keys
+// present on one side. Values other than maps of the same type are
+// compared like Compare.
+func CompareMapDiff(a, b interface{}) (bool, []string) {
+	v1, v2 := reflect.ValueOf(a), reflect.ValueOf(b)
+	if a == nil || b == nil || v1.Type() != v2.Type() || v1.Kind() != reflect.Map {
+		if equal, reason := Compare(a, b); !equal {
+			return false, []string{reason}
+		}
+		return true, nil
+	}
+	if v1.IsNil() != v2.IsNil() {
+		return false, []string{"one map is nil, one is not"}
+	}
+	type keyReason struct {
+		key    reflect.Value
+		reason string
+	}
+	var diffs []keyReason
+	opts := Options{}
+	for _, k := range v1.MapKeys() {
+		e2 := v2.MapIndex(k)
+		// a comparer per key, so the pairs which differed under a key
+		// are compared again under the next one
+		c := newComparer(&opts)
+		if !e2.IsValid() {
+			diffs = append(diffs, keyReason{k, keyElem(k).String() + " key only in first map"})
+		} else if equal, reason := c.descend(keyElem(k), v1.MapIndex(k), e2, 0, c.mapElemEqual); !equal {
+			diffs = append(diffs, keyReason{k, reason})
+		}
+	}
+	for _, k := range v2.MapKeys() {
+		if !v1.MapIndex(k).IsValid() {
+			diffs = append(diffs, keyReason{k, keyElem(k).String() + " key only in second map"})
+		}
+	}
+	if len(diffs) == 0 {
+		return true, nil
+	}
+	sort.Slice(diffs, func(i, j int) bool { return keyLess(diffs[i].key, diffs[j].key) })
+	reasons := make([]string, len(diffs))
+	for i, d := range diffs {
+		reasons[i] = d.reason
+	}
+	return false, reasons
+}
+
+// CompareMapContains tests that every key of the map subset is in the map
+// superset with an equal value (compared like with Compare), extra keys of
+// superset are allowed. Keys are checked in order, the first missing key
+// is reported as '[KEY] key missing in superset'. Values other than maps
+// of the same type are compared like Compare.
+func CompareMapContains(superset, subset interface{}) (bool, string) {
+	v1, v2 := reflect.ValueOf(superset), reflect.ValueOf(subset)
+	if superset == nil || subset == nil || v1.Type() != v2.Type() || v1.Kind() != reflect.Map {
+		return Compare(superset, subset)
+	}
+	keys := v2.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+	opts := Options{}
+	for _, k := range keys {
+		e1 := v1.MapIndex(k)
+		if !e1.IsValid() {
+			return false, keyElem(k).String() + " key missing in superset"
+		}
+		c := newComparer(&opts)
+		if equal, reason := c.descend(keyElem(k), e1, v2.MapIndex(k), 0, c.mapElemEqual); !equal {
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+// keyLess orders map keys: numbers and strings by value, others by their
+// formatted form.
+func keyLess(k1, k2 reflect.Value) bool {
+	switch k := k1.Kind(); {
+	case isInt(k):
+		return k1.Int() < k2.Int()
+	case isUint(k):
+		return k1.Uint() < k2.Uint()
+	case isFloat(k):
+		return k1.Float() < k2.Float()
+	case k == reflect.String:
+		return k1.String() < k2.String()
+	}
+	return fmt.Sprintf("%+v", valueInterface(k1)) < fmt.Sprintf("%+v", valueInterface(k2))
+}
@@ -0,0 +1,44 @@
+//go:build go1.21
+
+package deepequal
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// CompareOrderedMap compares maps with ordered keys like CompareMapDiff,
+// reporting every differing key in key order, without reflection for the
+// keys. Values are compared like with Compare.
+func CompareOrderedMap[K cmp.Ordered, V any](a, b map[K]V) (bool, []string) {
+	if (a == nil) != (b == nil) {
+		return false, []string{"one map is nil, one is not"}
+	}
+	keys := make([]K, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	var reasons []string
+	for _, k := range keys {
+		v1, ok1 := a[k]
+		v2, ok2 := b[k]
+		switch {
+		case !ok2:
+			reasons = append(reasons, fmt.Sprintf("[%+v] key only in first map", k))
+		case !ok1:
+			reasons = append(reasons, fmt.Sprintf("[%+v] key only in second map", k))
+		default:
+			if equal, reason := Compare(v1, v2); !equal {
+				reasons = append(reasons, fmt.Sprintf("[%+v] %s", k, reason))
+			}
+		}
+	}
+	return len(reasons) == 0, reasons
+}
@@ -0,0 +1,44 @@
+//go:build go1.21
+
+package deepequal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareOrderedMap(t *testing.T) {
+	equal, reasons := CompareOrderedMap(
+		map[int]string{10: "a", 2: "b", 1: "c", 3: "d"},
+		map[int]string{10: "x", 2: "b", 1: "z", 4: "d"},
+	)
+	want := []string{
+		"[1] scalar values differ",
+		"[3] key only in first map",
+		"[4] key only in second map",
+		"[10] scalar values differ",
+	}
+	if equal || !reflect.DeepEqual(reasons, want) {
+		t.Errorf("CompareOrderedMap() = %v, %q, want false, %q", equal, reasons, want)
+	}
+
+	equal, reasons = CompareOrderedMap(
+		map[string][]int{"host": {1}, "port": {80}, "debug": nil},
+		map[string][]int{"host": {2}, "port": {80}, "level": {1}},
+	)
+	want = []string{
+		"[debug] key only in first map",
+		"[host] [0] scalar values differ",
+		"[level] key only in second map",
+	}
+	if equal || !reflect.DeepEqual(reasons, want) {
+		t.Errorf("CompareOrderedMap() = %v, %q, want false, %q", equal, reasons, want)
+	}
+
+	if equal, reasons = CompareOrderedMap(map[string]int{"a": 1}, map[string]int{"a": 1}); !equal || reasons != nil {
+		t.Errorf("CompareOrderedMap() = %v, %q, want true", equal, reasons)
+	}
+	if equal, reasons = CompareOrderedMap(nil, map[string]int{}); equal || len(reasons) != 1 {
+		t.Errorf("CompareOrderedMap() = %v, %q, want a nil difference", equal, reasons)
+	}
+}
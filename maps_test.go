@@ -0,0 +1,441 @@
+package deepequal
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestEqualMap(t *testing.T) {
+	tests := []struct {
+		name       string
+		a          map[string]float64
+		b          map[string]float64
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal",
+			a:    map[string]float64{"a": 1, "b": 2},
+			b:    map[string]float64{"a": 1, "b": 2},
+			want: true,
+		},
+		{
+			name: "both nil",
+			want: true,
+		},
+		{
+			name: "NaN",
+			a:    map[string]float64{"a": math.NaN()},
+			b:    map[string]float64{"a": math.NaN()},
+			want: true,
+		},
+		{
+			name:       "nil and empty",
+			a:          nil,
+			b:          map[string]float64{},
+			want:       false,
+			wantReason: "one map is nil, one is not",
+		},
+		{
+			name:       "different lengths",
+			a:          map[string]float64{"a": 1},
+			b:          map[string]float64{"a": 1, "b": 2},
+			want:       false,
+			wantReason: "maps have different lengths",
+		},
+		{
+			name:       "value differ",
+			a:          map[string]float64{"a": 1, "b": 2},
+			b:          map[string]float64{"a": 1, "b": 3},
+			want:       false,
+			wantReason: "[b] scalar values differ",
+		},
+		{
+			name:       "NaN and number",
+			a:          map[string]float64{"a": math.NaN()},
+			b:          map[string]float64{"a": 1},
+			want:       false,
+			wantReason: "[a] scalar values differ",
+		},
+		{
+			name:       "missing key",
+			a:          map[string]float64{"a": 1},
+			b:          map[string]float64{"b": 1},
+			want:       false,
+			wantReason: "[a] invalid values are not equal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := EqualMap(tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("EqualMap() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("EqualMap() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+
+			got, gotReason = Compare(tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+type testSample struct {
+	F float64
+	S string
+}
+
+func TestEqualMap_ValueTypes(t *testing.T) {
+	nan := math.NaN()
+	if got, gotReason := EqualMap(map[int]testSample{1: {nan, "a"}}, map[int]testSample{1: {nan, "b"}}); got || gotReason != "[1] scalar values differ" {
+		t.Errorf("EqualMap() structs = %v, '%v', want false, '[1] scalar values differ'", got, gotReason)
+	}
+	if got, _ := EqualMap(map[int]testSample{1: {1, "a"}}, map[int]testSample{1: {1, "a"}}); !got {
+		t.Errorf("EqualMap() equal structs = %v, want true", got)
+	}
+	if got, _ := EqualMap(map[int]testCelsius{1: testCelsius(nan)}, map[int]testCelsius{1: testCelsius(nan)}); !got {
+		t.Errorf("EqualMap() named float NaN = %v, want true", got)
+	}
+	if got, _ := EqualMap(map[int][2]float64{1: {nan, 1}}, map[int][2]float64{1: {nan, 2}}); got {
+		t.Errorf("EqualMap() arrays = %v, want false", got)
+	}
+}
+
+func TestCompareMapDiff(t *testing.T) {
+	p, q := &testLimits{1, 2}, &testLimits{1, 3}
+	tests := []struct {
+		name        string
+		a           interface{}
+		b           interface{}
+		want        bool
+		wantReasons []string
+	}{
+		{
+			name: "equal",
+			a:    map[string]int{"a": 1, "b": 2},
+			b:    map[string]int{"a": 1, "b": 2},
+			want: true,
+		},
+		{
+			name: "several keys",
+			a:    map[string]interface{}{"host": "a", "port": 80, "tags": []string{"x"}, "debug": true},
+			b:    map[string]interface{}{"host": "b", "port": 80, "tags": []string{"y"}, "level": 1},
+			want: false,
+			wantReasons: []string{
+				"[debug] key only in first map",
+				"[host] scalar values differ",
+				"[level] key only in second map",
+				"[tags] [0] scalar values differ",
+			},
+		},
+		{
+			name:        "sorted numerically",
+			a:           map[int]string{2: "a", 10: "b", 1: "c"},
+			b:           map[int]string{2: "x", 10: "y", 1: "z"},
+			want:        false,
+			wantReasons: []string{"[1] scalar values differ", "[2] scalar values differ", "[10] scalar values differ"},
+		},
+		{
+			name:        "nil and empty",
+			a:           map[string]int(nil),
+			b:           map[string]int{},
+			want:        false,
+			wantReasons: []string{"one map is nil, one is not"},
+		},
+		{
+			name:        "shared pointers",
+			a:           map[string]*testLimits{"a": p, "b": p},
+			b:           map[string]*testLimits{"a": q, "b": q},
+			want:        false,
+			wantReasons: []string{"[a] struct.Max scalar values differ", "[b] struct.Max scalar values differ"},
+		},
+		{
+			name:        "not maps",
+			a:           []int{1},
+			b:           []int{2},
+			want:        false,
+			wantReasons: []string{"[0] scalar values differ"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReasons := CompareMapDiff(tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("CompareMapDiff() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotReasons, tt.wantReasons) {
+				t.Errorf("CompareMapDiff() got1 = %q, want %q", gotReasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestCompareMapContains(t *testing.T) {
+	tests := []struct {
+		name       string
+		superset   interface{}
+		subset     interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name:     "extra keys",
+			superset: map[string]interface{}{"host": "a", "port": 80, "debug": true},
+			subset:   map[string]interface{}{"host": "a", "port": 80},
+			want:     true,
+		},
+		{
+			name:     "empty subset",
+			superset: map[string]int{"a": 1},
+			subset:   map[string]int{},
+			want:     true,
+		},
+		{
+			name:       "missing key",
+			superset:   map[string]int{"a": 1},
+			subset:     map[string]int{"c": 3, "b": 2, "a": 1},
+			want:       false,
+			wantReason: "[b] key missing in superset",
+		},
+		{
+			name:       "value differs",
+			superset:   map[string][]int{"a": {1, 2}, "b": nil},
+			subset:     map[string][]int{"a": {1, 3}},
+			want:       false,
+			wantReason: "[a] [1] scalar values differ",
+		},
+		{
+			name:       "not maps",
+			superset:   []int{1},
+			subset:     []int{2},
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+		{
+			name:       "different types",
+			superset:   map[string]int{"a": 1},
+			subset:     map[string]int64{"a": 1},
+			want:       false,
+			wantReason: "values are of different types: map[string]int vs map[string]int64",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareMapContains(tt.superset, tt.subset)
+			if got != tt.want {
+				t.Errorf("CompareMapContains() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareMapContains() got1 = %q, want %q", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func benchMaps() (map[string]int, map[string]int) {
+	a := make(map[string]int, 1000)
+	b := make(map[string]int, 1000)
+	for i := 0; i < 1000; i++ {
+		a[strconv.Itoa(i)] = i
+		b[strconv.Itoa(i)] = i
+	}
+	return a, b
+}
+
+func BenchmarkEqualMap(b *testing.B) {
+	m1, m2 := benchMaps()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if equal, reason := EqualMap(m1, m2); !equal {
+			b.Fatal(reason)
+		}
+	}
+}
+
+func BenchmarkCompareMap(b *testing.B) {
+	m1, m2 := benchMaps()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if equal, reason := Compare(m1, m2); !equal {
+			b.Fatal(reason)
+		}
+	}
+}
+
+func TestCompareWithOptions_CaseInsensitiveMapKeys(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "different case",
+			a1:   map[string]string{"Content-Type": "json", "accept": "*"},
+			a2:   map[string]string{"content-type": "json", "ACCEPT": "*"},
+			opts: Options{CaseInsensitiveMapKeys: true},
+			want: true,
+		},
+		{
+			name:       "value differ",
+			a1:         map[string]string{"Content-Type": "json"},
+			a2:         map[string]string{"content-type": "xml"},
+			opts:       Options{CaseInsensitiveMapKeys: true},
+			want:       false,
+			wantReason: "[Content-Type] scalar values differ",
+		},
+		{
+			name:       "no match",
+			a1:         map[string]int{"Accept": 1},
+			a2:         map[string]int{"Accepts": 1},
+			opts:       Options{CaseInsensitiveMapKeys: true},
+			want:       false,
+			wantReason: "[Accept] key not found ignoring case",
+		},
+		{
+			name:       "ambiguous",
+			a1:         map[string]int{"a": 1, "b": 2},
+			a2:         map[string]int{"a": 1, "A": 2},
+			opts:       Options{CaseInsensitiveMapKeys: true},
+			want:       false,
+			wantReason: "second map keys \"A\" and \"a\" are ambiguous ignoring case",
+		},
+		{
+			name:       "disabled",
+			a1:         map[string]int{"Accept": 1},
+			a2:         map[string]int{"accept": 1},
+			want:       false,
+			wantReason: "[Accept] invalid values are not equal",
+		},
+	})
+}
+
+type testMapKey struct {
+	A int
+	B string
+}
+
+func TestCompareWithOptions_DeterministicMapOrder(t *testing.T) {
+	opts := Options{DeterministicMapOrder: true}
+	a1 := map[int]string{}
+	a2 := map[int]string{}
+	for i := 0; i < 100; i++ {
+		a1[i], a2[i] = "a", "b"
+	}
+	tests := []optionsTest{
+		{
+			name:       "int keys",
+			a1:         a1,
+			a2:         a2,
+			opts:       opts,
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+		{
+			name:       "string keys",
+			a1:         map[string]int{"b": 1, "c": 1, "a": 1, "d": 1},
+			a2:         map[string]int{"b": 2, "c": 2, "a": 1, "d": 2},
+			opts:       opts,
+			want:       false,
+			wantReason: "[b] scalar values differ",
+		},
+		{
+			name:       "struct keys",
+			a1:         map[testMapKey]int{{2, "a"}: 1, {1, "b"}: 1, {1, "a"}: 1},
+			a2:         map[testMapKey]int{{2, "a"}: 2, {1, "b"}: 2, {1, "a"}: 2},
+			opts:       opts,
+			want:       false,
+			wantReason: "[{A:1 B:a}] scalar values differ",
+		},
+		{
+			name:       "case insensitive",
+			a1:         map[string]int{"B": 1, "C": 1, "A": 1},
+			a2:         map[string]int{"b": 2, "c": 2, "a": 2},
+			opts:       Options{DeterministicMapOrder: true, CaseInsensitiveMapKeys: true},
+			want:       false,
+			wantReason: "[A] scalar values differ",
+		},
+	}
+	// the random map order would show up over several runs
+	for i := 0; i < 20; i++ {
+		runOptionsTests(t, tests)
+	}
+}
+
+func TestCompareWithOptions_UnorderedMapValueSlices(t *testing.T) {
+	opts := Options{UnorderedMapValueSlices: true}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "sets",
+			a1:   map[string][]string{"admin": {"alice", "bob"}, "dev": {"carol"}},
+			a2:   map[string][]string{"admin": {"bob", "alice"}, "dev": {"carol"}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "unmatched element",
+			a1:         map[string][]string{"admin": {"alice", "bob"}},
+			a2:         map[string][]string{"admin": {"bob", "dave"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[admin] [0] unmatched element alice",
+		},
+		{
+			name:       "lengths",
+			a1:         map[string][]int{"a": {1, 2}},
+			a2:         map[string][]int{"a": {2}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[a] slices have different lengths",
+		},
+		{
+			name:       "nil slice",
+			a1:         map[string][]int{"a": nil},
+			a2:         map[string][]int{"a": {}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[a] one slice is nil, the other is not",
+		},
+		{
+			name: "with MaxSliceElements",
+			a1:   map[string][]int{"a": {1, 2, 3}},
+			a2:   map[string][]int{"a": {2, 1, 4}},
+			opts: Options{UnorderedMapValueSlices: true, MaxSliceElements: 2},
+			want: true,
+		},
+		{
+			name:       "top-level slice",
+			a1:         []int{1, 2},
+			a2:         []int{2, 1},
+			opts:       opts,
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+		{
+			name:       "struct field",
+			a1:         testDeploymentConfig{Limits: map[string]testLimits{}, Tags: []string{"a", "b"}},
+			a2:         testDeploymentConfig{Limits: map[string]testLimits{}, Tags: []string{"b", "a"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Tags [0] scalar values differ",
+		},
+		{
+			name:       "nested slices",
+			a1:         map[string][][]int{"a": {{1, 2}, {3}}},
+			a2:         map[string][][]int{"a": {{3}, {2, 1}}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[a] [0] unmatched element [1 2]",
+		},
+		{
+			name:       "disabled",
+			a1:         map[string][]string{"admin": {"alice", "bob"}},
+			a2:         map[string][]string{"admin": {"bob", "alice"}},
+			want:       false,
+			wantReason: "[admin] [0] scalar values differ",
+		},
+	})
+}
@@ -0,0 +1,232 @@
+package deepequal
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+var stringType = reflect.TypeOf("")
+
+// addressable returns an addressable value holding v, copying v if needed.
+// It fails for values obtained through unexported fields.
+func addressable(v reflect.Value) (reflect.Value, bool) {
+	if !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+	if v.CanAddr() {
+		return v, true
+	}
+	p := reflect.New(v.Type()).Elem()
+	p.Set(v)
+	return p, true
+}
+
+// findMethod looks up the named method of the type of v, with value or
+// pointer receiver, and returns it along with the receiver to call it on.
+func findMethod(v reflect.Value, name string) (reflect.Method, reflect.Value, bool) {
+	if v.Kind() == reflect.Interface || !v.CanInterface() {
+		return reflect.Method{}, reflect.Value{}, false
+	}
+	t := v.Type()
+	if m, ok := t.MethodByName(name); ok {
+		return m, v, true
+	}
+	if t.Kind() == reflect.Ptr {
+		return reflect.Method{}, reflect.Value{}, false
+	}
+	m, ok := reflect.PtrTo(t).MethodByName(name)
+	if !ok {
+		return reflect.Method{}, reflect.Value{}, false
+	}
+	recv, ok := addressable(v)
+	if !ok {
+		return reflect.Method{}, reflect.Value{}, false
+	}
+	return m, recv.Addr(), true
+}
+
+// isProtoMessage tells if the type of v has the methods of generated
+// protobuf messages: Reset(), String() string and ProtoReflect().
+func isProtoMessage(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	t := v.Type()
+	reset, ok := t.MethodByName("Reset")
+	if !ok || reset.Type.NumIn() != 1 || reset.Type.NumOut() != 0 {
+		return false
+	}
+	str, ok := t.MethodByName("String")
+	if !ok || str.Type.NumIn() != 1 || str.Type.NumOut() != 1 || str.Type.Out(0) != stringType {
+		return false
+	}
+	pr, ok := t.MethodByName("ProtoReflect")
+	return ok && pr.Type.NumIn() == 1 && pr.Type.NumOut() == 1
+}
+
+// diffMethod compares v1 and v2 with the method Diff(T) string of their
+// type T. handled is false if there is no such method.
+func diffMethod(v1, v2 reflect.Value) (handled, equal bool, reason string) {
+	m, recv, ok := findMethod(v1, "Diff")
+	if !ok || !v2.CanInterface() {
+		return false, false, ""
+	}
+	mt := m.Type
+	if mt.NumIn() != 2 || mt.In(1) != v1.Type() || mt.NumOut() != 1 || mt.Out(0) != stringType {
+		return false, false, ""
+	}
+	if recv.Kind() == reflect.Ptr && recv.IsNil() {
+		return false, false, ""
+	}
+	reason = m.Func.Call([]reflect.Value{recv, v2})[0].String()
+	return true, reason == "", reason
+}
+
+// stringMethod compares v1 and v2 by the results of their String() method.
+// handled is false if there is no such method.
+func stringMethod(v1, v2 reflect.Value) (handled, equal bool, reason string) {
+	m1, recv1, ok := findMethod(v1, "String")
+	if !ok || m1.Type.NumIn() != 1 || m1.Type.NumOut() != 1 || m1.Type.Out(0) != stringType {
+		return false, false, ""
+	}
+	_, recv2, ok := findMethod(v2, "String")
+	if !ok {
+		return false, false, ""
+	}
+	if recv1.Kind() == reflect.Ptr && (recv1.IsNil() || recv2.IsNil()) {
+		return false, false, ""
+	}
+	s1 := m1.Func.Call([]reflect.Value{recv1})[0].String()
+	s2 := m1.Func.Call([]reflect.Value{recv2})[0].String()
+	if s1 == s2 {
+		return true, true, ""
+	}
+	return true, false, fmt.Sprintf("stringer values differ: %q != %q", s1, s2)
+}
+
+// canonicalMethod normalizes v1 and v2 with the method Canonical() T of
+// their type T. ok is false if there is no such method.
+func canonicalMethod(v1, v2 reflect.Value) (n1, n2 reflect.Value, ok bool) {
+	m, recv1, ok := findMethod(v1, "Canonical")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 || m.Type.Out(0) != v1.Type() {
+		return n1, n2, false
+	}
+	_, recv2, ok := findMethod(v2, "Canonical")
+	if !ok {
+		return n1, n2, false
+	}
+	if recv1.Kind() == reflect.Ptr && (recv1.IsNil() || recv2.IsNil()) {
+		return n1, n2, false
+	}
+	n1 = m.Func.Call([]reflect.Value{recv1})[0]
+	n2 = m.Func.Call([]reflect.Value{recv2})[0]
+	return n1, n2, true
+}
+
+// callMethod calls the method without arguments on v and returns its
+// result, or the results as a slice of interface{} if there are several.
+func callMethod(v reflect.Value, name string) reflect.Value {
+	out := v.MethodByName(name).Call(nil)
+	if len(out) == 1 {
+		return out[0]
+	}
+	results := make([]interface{}, len(out))
+	for i, r := range out {
+		results[i] = r.Interface()
+	}
+	return reflect.ValueOf(results)
+}
+
+// compareByInterface compares v1 and v2 which implement iface by the
+// results of its methods without arguments. handled is false if either
+// doesn't implement it or is a nil pointer, whose methods may panic.
+func (c *comparer) compareByInterface(iface reflect.Type, v1, v2 reflect.Value, depth int) (handled, equal bool, reason string) {
+	if !v1.CanInterface() || !v2.CanInterface() || !v1.Type().Implements(iface) || !v2.Type().Implements(iface) {
+		return false, false, ""
+	}
+	if (v1.Kind() == reflect.Ptr && v1.IsNil()) || (v2.Kind() == reflect.Ptr && v2.IsNil()) {
+		return false, false, ""
+	}
+	equal = true
+	for i := 0; i < iface.NumMethod(); i++ {
+		m := iface.Method(i)
+		if m.Type.NumIn() != 0 || m.Type.NumOut() == 0 {
+			continue
+		}
+		r1, r2 := callMethod(v1, m.Name), callMethod(v2, m.Name)
+		if eq, r := c.descend(methodElem(m.Name), r1, r2, depth, c.deepValueEqual); !eq {
+			if !c.all {
+				return true, false, r
+			}
+			equal = false
+		}
+	}
+	return true, equal, ""
+}
+
+// publicMethodsEqual compares the structs v1 and v2 by the results of the
+// exported methods without arguments with value receiver, which get copies
+// of them. Methods with pointer receiver may change the struct (like
+// sync.Mutex.TryLock), so they aren't called. Values obtained through
+// unexported fields can't be called, so they are skipped.
+func (c *comparer) publicMethodsEqual(v1, v2 reflect.Value, depth int) (bool, string) {
+	if !v1.CanInterface() || !v2.CanInterface() {
+		return true, ""
+	}
+	t := v1.Type()
+	c.inMethods = true
+	defer func() { c.inMethods = false }()
+	result := true
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if m.Type.NumIn() != 1 || m.Type.NumOut() == 0 {
+			continue
+		}
+		m1, m2 := callMethod(v1, m.Name), callMethod(v2, m.Name)
+		if equal, reason := c.descend(methodElem(m.Name), m1, m2, depth, c.deepValueEqual); !equal {
+			if !c.all {
+				return false, reason
+			}
+			result = false
+		}
+	}
+	return result, ""
+}
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// marshalBinary returns the result of the MarshalBinary method of v, if it
+// has the one of encoding.BinaryMarshaler and it doesn't fail.
+func marshalBinary(v reflect.Value) ([]byte, bool) {
+	m, recv, ok := findMethod(v, "MarshalBinary")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 2 || m.Type.Out(0) != bytesType || m.Type.Out(1) != errorType {
+		return nil, false
+	}
+	if recv.Kind() == reflect.Ptr && recv.IsNil() {
+		return nil, false
+	}
+	out := m.Func.Call([]reflect.Value{recv})
+	if !out[1].IsNil() {
+		return nil, false
+	}
+	return out[0].Bytes(), true
+}
+
+// binaryMarshaler compares v1 and v2 by their MarshalBinary results.
+// handled is false if they don't implement encoding.BinaryMarshaler or it
+// fails.
+func binaryMarshaler(v1, v2 reflect.Value) (handled, equal bool, reason string) {
+	b1, ok := marshalBinary(v1)
+	if !ok {
+		return false, false, ""
+	}
+	b2, ok := marshalBinary(v2)
+	if !ok {
+		return false, false, ""
+	}
+	if bytes.Equal(b1, b2) {
+		return true, true, ""
+	}
+	return true, false, "binary representations differ"
+}
@@ -0,0 +1,655 @@
+package deepequal
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMoney struct {
+	Amount   int
+	Currency string
+}
+
+func (m testMoney) Diff(o testMoney) string {
+	if m.Currency != o.Currency {
+		return fmt.Sprintf("currency %s != %s", m.Currency, o.Currency)
+	}
+	if m.Amount != o.Amount {
+		return fmt.Sprintf("amount %d != %d", m.Amount, o.Amount)
+	}
+	return ""
+}
+
+type testVersion struct {
+	Major, Minor int
+	Label        string
+}
+
+// Diff ignores Label.
+func (v *testVersion) Diff(o testVersion) string {
+	if v.Major != o.Major || v.Minor != o.Minor {
+		return fmt.Sprintf("version %d.%d != %d.%d", v.Major, v.Minor, o.Major, o.Minor)
+	}
+	return ""
+}
+
+type testDiffHolder struct {
+	Price   testMoney
+	Version testVersion
+	Ptr     *testVersion
+}
+
+func TestCompareWithOptions_UseDiffMethod(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		opts       Options
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "value receiver equal",
+			a1:   testMoney{Amount: 1, Currency: "USD"},
+			a2:   testMoney{Amount: 1, Currency: "USD"},
+			opts: Options{UseDiffMethod: true},
+			want: true,
+		},
+		{
+			name:       "value receiver differ",
+			a1:         testMoney{Amount: 1, Currency: "USD"},
+			a2:         testMoney{Amount: 2, Currency: "USD"},
+			opts:       Options{UseDiffMethod: true},
+			want:       false,
+			wantReason: "amount 1 != 2",
+		},
+		{
+			name:       "pointer receiver on unaddressable value",
+			a1:         testVersion{Major: 1, Minor: 2, Label: "a"},
+			a2:         testVersion{Major: 1, Minor: 3, Label: "a"},
+			opts:       Options{UseDiffMethod: true},
+			want:       false,
+			wantReason: "version 1.2 != 1.3",
+		},
+		{
+			name: "pointer receiver ignores label",
+			a1:   testVersion{Major: 1, Minor: 2, Label: "a"},
+			a2:   testVersion{Major: 1, Minor: 2, Label: "b"},
+			opts: Options{UseDiffMethod: true},
+			want: true,
+		},
+		{
+			name: "nested fields",
+			a1: testDiffHolder{
+				Price:   testMoney{Amount: 1, Currency: "USD"},
+				Version: testVersion{Major: 1, Label: "a"},
+				Ptr:     &testVersion{Major: 2, Label: "a"},
+			},
+			a2: testDiffHolder{
+				Price:   testMoney{Amount: 1, Currency: "USD"},
+				Version: testVersion{Major: 1, Label: "b"},
+				Ptr:     &testVersion{Major: 3, Label: "a"},
+			},
+			opts:       Options{UseDiffMethod: true},
+			want:       false,
+			wantReason: "struct.Ptr version 2.0 != 3.0",
+		},
+		{
+			name:       "disabled",
+			a1:         testVersion{Major: 1, Minor: 2, Label: "a"},
+			a2:         testVersion{Major: 1, Minor: 2, Label: "b"},
+			want:       false,
+			wantReason: "struct.Label scalar values differ",
+		},
+		{
+			name:       "no method",
+			a1:         testStruct{Name: "a"},
+			a2:         testStruct{Name: "b"},
+			opts:       Options{UseDiffMethod: true},
+			want:       false,
+			wantReason: "struct.Name scalar values differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareWithOptions(tt.a1, tt.a2, tt.opts)
+			if got != tt.want {
+				t.Errorf("CompareWithOptions() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareWithOptions() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+type testColor int
+
+const (
+	testRed testColor = iota
+	testGreen
+)
+
+func (c testColor) String() string {
+	switch c {
+	case testRed:
+		return "red"
+	case testGreen:
+		return "green"
+	}
+	return "unknown"
+}
+
+type testID struct {
+	id string
+}
+
+func (i *testID) String() string {
+	return i.id
+}
+
+type testPalette struct {
+	Main   testColor
+	Colors []testColor
+	ID     testID
+}
+
+func TestCompareWithOptions_StringerTypes(t *testing.T) {
+	stringers := map[reflect.Type]bool{
+		reflect.TypeOf(testColor(0)): true,
+		reflect.TypeOf(testID{}):     true,
+	}
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		opts       Options
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal enum",
+			a1:   testRed,
+			a2:   testRed,
+			opts: Options{StringerTypes: stringers},
+			want: true,
+		},
+		{
+			name:       "differ enum",
+			a1:         testRed,
+			a2:         testGreen,
+			opts:       Options{StringerTypes: stringers},
+			want:       false,
+			wantReason: `stringer values differ: "red" != "green"`,
+		},
+		{
+			name: "equal by string",
+			a1:   testColor(5),
+			a2:   testColor(6),
+			opts: Options{StringerTypes: stringers},
+			want: true,
+		},
+		{
+			name:       "not listed",
+			a1:         testColor(5),
+			a2:         testColor(6),
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name:       "nested",
+			a1:         testPalette{Main: testRed, Colors: []testColor{testRed, testGreen}, ID: testID{"a"}},
+			a2:         testPalette{Main: testRed, Colors: []testColor{testRed, testRed}, ID: testID{"a"}},
+			opts:       Options{StringerTypes: stringers},
+			want:       false,
+			wantReason: `struct.Colors [1] stringer values differ: "green" != "red"`,
+		},
+		{
+			name: "pointer receiver hides unexported fields",
+			a1:   testPalette{ID: testID{"a"}},
+			a2:   testPalette{ID: testID{"a"}},
+			opts: Options{StringerTypes: stringers},
+			want: true,
+		},
+		{
+			name:       "pointer receiver differ",
+			a1:         testPalette{ID: testID{"a"}},
+			a2:         testPalette{ID: testID{"b"}},
+			opts:       Options{StringerTypes: stringers},
+			want:       false,
+			wantReason: `struct.ID stringer values differ: "a" != "b"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareWithOptions(tt.a1, tt.a2, tt.opts)
+			if got != tt.want {
+				t.Errorf("CompareWithOptions() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareWithOptions() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+type testTags struct {
+	Name string
+	Tags []string
+}
+
+func (t testTags) Canonical() testTags {
+	tags := append([]string(nil), t.Tags...)
+	sort.Strings(tags)
+	return testTags{Name: t.Name, Tags: tags}
+}
+
+type testSet struct {
+	Items []int
+}
+
+func (s *testSet) Canonical() testSet {
+	items := append([]int(nil), s.Items...)
+	sort.Ints(items)
+	return testSet{Items: items}
+}
+
+type testCanonicalHolder struct {
+	Tags testTags
+	Set  testSet
+}
+
+func TestCompareWithOptions_UseCanonicalMethod(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "value receiver",
+			a1:   testTags{Name: "a", Tags: []string{"x", "y", "z"}},
+			a2:   testTags{Name: "a", Tags: []string{"z", "x", "y"}},
+			opts: Options{UseCanonicalMethod: true},
+			want: true,
+		},
+		{
+			name:       "value receiver differ",
+			a1:         testTags{Name: "a", Tags: []string{"x", "y", "z"}},
+			a2:         testTags{Name: "a", Tags: []string{"z", "x", "x"}},
+			opts:       Options{UseCanonicalMethod: true},
+			want:       false,
+			wantReason: "struct.Tags [1] scalar values differ",
+		},
+		{
+			name: "pointer receiver",
+			a1:   testCanonicalHolder{Set: testSet{Items: []int{3, 1, 2}}},
+			a2:   testCanonicalHolder{Set: testSet{Items: []int{1, 2, 3}}},
+			opts: Options{UseCanonicalMethod: true},
+			want: true,
+		},
+		{
+			name: "pointers",
+			a1:   &testSet{Items: []int{3, 1, 2}},
+			a2:   &testSet{Items: []int{1, 2, 3}},
+			opts: Options{UseCanonicalMethod: true},
+			want: true,
+		},
+		{
+			name:       "pointer receiver differ",
+			a1:         testCanonicalHolder{Set: testSet{Items: []int{3, 1, 2}}},
+			a2:         testCanonicalHolder{Set: testSet{Items: []int{1, 2}}},
+			opts:       Options{UseCanonicalMethod: true},
+			want:       false,
+			wantReason: "struct.Set struct.Items slices have different lengths",
+		},
+		{
+			name:       "disabled",
+			a1:         testTags{Name: "a", Tags: []string{"x", "y"}},
+			a2:         testTags{Name: "a", Tags: []string{"y", "x"}},
+			want:       false,
+			wantReason: "struct.Tags [0] scalar values differ",
+		},
+	})
+}
+
+type testPlugin interface {
+	Name() string
+	Version() (int, int)
+	Run(arg string) error
+}
+
+type testPluginA struct {
+	Title string
+	Ver   int
+}
+
+func (p testPluginA) Name() string             { return p.Title }
+func (p testPluginA) Version() (int, int)      { return p.Ver, 0 }
+func (p testPluginA) Run(arg string) error     { return nil }
+func (p testPluginA) Internal() map[string]int { return nil }
+
+type testPluginB struct {
+	ID    string
+	Major int
+	Minor int
+}
+
+func (p *testPluginB) Name() string         { return p.ID }
+func (p *testPluginB) Version() (int, int)  { return p.Major, p.Minor }
+func (p *testPluginB) Run(arg string) error { return nil }
+
+type testPluginHolder struct {
+	Plugin testPlugin
+	Any    interface{}
+}
+
+func TestCompareWithOptions_CompareByInterface(t *testing.T) {
+	iface := reflect.TypeOf((*testPlugin)(nil)).Elem()
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "different implementations",
+			a1:   testPluginHolder{Plugin: testPluginA{Title: "p", Ver: 1}},
+			a2:   testPluginHolder{Plugin: &testPluginB{ID: "p", Major: 1}},
+			opts: Options{CompareByInterface: iface},
+			want: true,
+		},
+		{
+			name: "interface{} field",
+			a1:   testPluginHolder{Any: testPluginA{Title: "p", Ver: 1}},
+			a2:   testPluginHolder{Any: &testPluginB{ID: "p", Major: 1}},
+			opts: Options{CompareByInterface: iface},
+			want: true,
+		},
+		{
+			name:       "name differ",
+			a1:         testPluginHolder{Plugin: testPluginA{Title: "p", Ver: 1}},
+			a2:         testPluginHolder{Plugin: &testPluginB{ID: "q", Major: 1}},
+			opts:       Options{CompareByInterface: iface},
+			want:       false,
+			wantReason: "struct.Plugin Name() scalar values differ",
+		},
+		{
+			name:       "version differ",
+			a1:         testPluginHolder{Plugin: testPluginA{Title: "p", Ver: 1}},
+			a2:         testPluginHolder{Plugin: &testPluginB{ID: "p", Major: 1, Minor: 2}},
+			opts:       Options{CompareByInterface: iface},
+			want:       false,
+			wantReason: "struct.Plugin Version() [1] scalar values differ",
+		},
+		{
+			name:       "nil pointer",
+			a1:         testPluginHolder{Plugin: (*testPluginA)(nil)},
+			a2:         testPluginHolder{Plugin: &testPluginA{Title: "p", Ver: 1}},
+			opts:       Options{CompareByInterface: iface},
+			want:       false,
+			wantReason: "struct.Plugin one pointer is nil, the other is not",
+		},
+		{
+			name: "nil pointers",
+			a1:   testPluginHolder{Plugin: (*testPluginA)(nil)},
+			a2:   testPluginHolder{Plugin: (*testPluginA)(nil)},
+			opts: Options{CompareByInterface: iface},
+			want: true,
+		},
+		{
+			name:       "nil pointer of other implementation",
+			a1:         testPluginHolder{Plugin: (*testPluginA)(nil)},
+			a2:         testPluginHolder{Plugin: &testPluginB{ID: "p", Major: 1}},
+			opts:       Options{CompareByInterface: iface},
+			want:       false,
+			wantReason: "struct.Plugin values are of differing types: *deepequal.testPluginA vs *deepequal.testPluginB",
+		},
+		{
+			name:       "not implemented",
+			a1:         testPluginHolder{Any: testPluginA{Title: "p", Ver: 1}},
+			a2:         testPluginHolder{Any: 1},
+			opts:       Options{CompareByInterface: iface},
+			want:       false,
+			wantReason: "struct.Any values are of differing types: deepequal.testPluginA vs int",
+		},
+		{
+			name:       "disabled",
+			a1:         testPluginHolder{Plugin: testPluginA{Title: "p", Ver: 1}},
+			a2:         testPluginHolder{Plugin: &testPluginB{ID: "p", Major: 1}},
+			want:       false,
+			wantReason: "struct.Plugin values are of differing types: deepequal.testPluginA vs *deepequal.testPluginB",
+		},
+	})
+}
+
+type testEvent struct {
+	Name string
+	At   time.Time
+}
+
+// testBadMarshaler fails to marshal, so it's compared field by field.
+type testBadMarshaler struct {
+	Value int
+}
+
+func (testBadMarshaler) MarshalBinary() ([]byte, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestCompareWithOptions_UseBinaryMarshaler(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "equal times",
+			a1:   testEvent{Name: "a", At: now},
+			a2:   testEvent{Name: "a", At: time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)},
+			opts: Options{UseBinaryMarshaler: true},
+			want: true,
+		},
+		{
+			name:       "different times",
+			a1:         testEvent{Name: "a", At: now},
+			a2:         testEvent{Name: "a", At: now.Add(time.Second)},
+			opts:       Options{UseBinaryMarshaler: true},
+			want:       false,
+			wantReason: "struct.At binary representations differ",
+		},
+		{
+			name: "top level",
+			a1:   now,
+			a2:   now.Add(0),
+			opts: Options{UseBinaryMarshaler: true},
+			want: true,
+		},
+		{
+			name: "marshal error falls back",
+			a1:   testBadMarshaler{Value: 1},
+			a2:   testBadMarshaler{Value: 1},
+			opts: Options{UseBinaryMarshaler: true},
+			want: true,
+		},
+		{
+			name:       "marshal error falls back differ",
+			a1:         testBadMarshaler{Value: 1},
+			a2:         testBadMarshaler{Value: 2},
+			opts:       Options{UseBinaryMarshaler: true},
+			want:       false,
+			wantReason: "struct.Value scalar values differ",
+		},
+		{
+			name:       "disabled",
+			a1:         testEvent{Name: "a", At: now},
+			a2:         testEvent{Name: "a", At: now},
+			want:       false,
+			wantReason: "struct.At struct.wall unexported",
+		},
+	})
+}
+
+type testCounter struct {
+	Name string
+	hits []int
+}
+
+func (c testCounter) Count() int { return len(c.hits) }
+
+func (c *testCounter) Last() (int, bool) {
+	if len(c.hits) == 0 {
+		return 0, false
+	}
+	return c.hits[len(c.hits)-1], true
+}
+
+// Reset has an argument, so it's not called.
+func (c *testCounter) Reset(n int) { c.hits = c.hits[:n] }
+
+type testCounterHolder struct {
+	Counter testCounter
+	At      time.Time
+}
+
+func TestCompareWithOptions_PublicSurfaceOnly(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "same public surface",
+			a1:   testCounter{Name: "a", hits: []int{1, 2}},
+			a2:   testCounter{Name: "a", hits: []int{5, 2}},
+			opts: Options{PublicSurfaceOnly: true},
+			want: true,
+		},
+		{
+			name:       "value receiver method differs",
+			a1:         testCounter{Name: "a", hits: []int{1, 2}},
+			a2:         testCounter{Name: "a", hits: []int{2}},
+			opts:       Options{PublicSurfaceOnly: true},
+			want:       false,
+			wantReason: "Count() scalar values differ",
+		},
+		{
+			name: "pointer receiver method not called",
+			a1:   testCounter{Name: "a", hits: []int{1, 2}},
+			a2:   testCounter{Name: "a", hits: []int{1, 3}},
+			opts: Options{PublicSurfaceOnly: true},
+			want: true,
+		},
+		{
+			name:       "exported field differs",
+			a1:         testCounter{Name: "a"},
+			a2:         testCounter{Name: "b"},
+			opts:       Options{PublicSurfaceOnly: true},
+			want:       false,
+			wantReason: "struct.Name scalar values differ",
+		},
+		{
+			name: "nested with time",
+			a1:   testCounterHolder{Counter: testCounter{hits: []int{1}}, At: now},
+			a2:   testCounterHolder{Counter: testCounter{hits: []int{1}}, At: now.In(time.UTC)},
+			opts: Options{PublicSurfaceOnly: true},
+			want: true,
+		},
+		{
+			name:       "nested differs",
+			a1:         testCounterHolder{Counter: testCounter{hits: []int{1}}, At: now},
+			a2:         testCounterHolder{Counter: testCounter{hits: []int{1, 2}}, At: now},
+			opts:       Options{PublicSurfaceOnly: true},
+			want:       false,
+			wantReason: "struct.Counter Count() scalar values differ",
+		},
+		{
+			name:       "disabled",
+			a1:         testCounter{Name: "a", hits: []int{1, 2}},
+			a2:         testCounter{Name: "a", hits: []int{5, 2}},
+			want:       false,
+			wantReason: "struct.hits unexported",
+		},
+	})
+}
+
+type testGuarded struct {
+	Mu    sync.Mutex
+	Items *list.List
+	Buf   *bytes.Buffer
+}
+
+func TestCompareWithOptions_PublicSurfaceOnlyState(t *testing.T) {
+	a1 := &testGuarded{Items: list.New(), Buf: bytes.NewBufferString("ab")}
+	a2 := &testGuarded{Items: list.New(), Buf: bytes.NewBufferString("ab")}
+	a1.Items.PushBack(1)
+	a2.Items.PushBack(1)
+	if got, reason := CompareWithOptions(a1, a2, Options{PublicSurfaceOnly: true}); !got {
+		t.Errorf("CompareWithOptions() = %v, '%v', want true", got, reason)
+	}
+	if !a1.Mu.TryLock() || !a2.Mu.TryLock() {
+		t.Error("CompareWithOptions() left the mutex locked")
+	}
+	if a1.Items.Len() != 1 || a1.Buf.String() != "ab" {
+		t.Errorf("CompareWithOptions() changed the values: list length %d, buffer %q", a1.Items.Len(), a1.Buf.String())
+	}
+}
+
+func TestCompareWithOptions_PublicSurfaceOnlyTimes(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	a1 := testCounterHolder{At: now}
+	a2 := testCounterHolder{At: now.Add(time.Hour)}
+	if got, reason := CompareWithOptions(a1, a2, Options{PublicSurfaceOnly: true}); got || reason == "" {
+		t.Errorf("CompareWithOptions() = %v, '%v', want a difference", got, reason)
+	}
+}
+
+// testProto has the shape of a generated protobuf message: its cache
+// differs between equal messages.
+type testProto struct {
+	Id    int
+	cache []byte
+}
+
+func (m *testProto) Reset()                    { *m = testProto{} }
+func (m *testProto) String() string            { return fmt.Sprintf("id:%d", m.Id) }
+func (m *testProto) ProtoReflect() interface{} { return m }
+
+type testProtoHolder struct {
+	Msg  *testProto
+	Msgs []*testProto
+}
+
+func TestCompareWithOptions_ProtoEqual(t *testing.T) {
+	protoEqual := func(a, b interface{}) bool {
+		m1, m2 := a.(*testProto), b.(*testProto)
+		if m1 == nil || m2 == nil {
+			return m1 == m2
+		}
+		return m1.Id == m2.Id
+	}
+	opts := Options{ProtoEqual: protoEqual}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "equal",
+			a1:   &testProto{Id: 1, cache: []byte{1}},
+			a2:   &testProto{Id: 1},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "differ",
+			a1:         &testProto{Id: 1},
+			a2:         &testProto{Id: 2},
+			opts:       opts,
+			want:       false,
+			wantReason: "proto messages differ",
+		},
+		{
+			name:       "nested",
+			a1:         testProtoHolder{Msg: &testProto{Id: 1, cache: []byte{1}}, Msgs: []*testProto{{Id: 2}, nil}},
+			a2:         testProtoHolder{Msg: &testProto{Id: 1}, Msgs: []*testProto{{Id: 2}, {Id: 3}}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Msgs [1] proto messages differ",
+		},
+		{
+			name:       "disabled",
+			a1:         &testProto{Id: 1, cache: []byte{1}},
+			a2:         &testProto{Id: 1},
+			want:       false,
+			wantReason: "struct.cache unexported",
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package deepequal
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || isFloat(k)
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch k := v.Kind(); {
+	case isInt(k):
+		return float64(v.Int())
+	case isUint(k):
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+// numbersEqual compares numeric values of any integer or float kinds by
+// value. Integers are compared exactly, NaN is equal to NaN.
+func numbersEqual(v1, v2 reflect.Value) bool {
+	k1, k2 := v1.Kind(), v2.Kind()
+	switch {
+	case isInt(k1) && isInt(k2):
+		return v1.Int() == v2.Int()
+	case isUint(k1) && isUint(k2):
+		return v1.Uint() == v2.Uint()
+	case isInt(k1) && isUint(k2):
+		return v1.Int() >= 0 && uint64(v1.Int()) == v2.Uint()
+	case isUint(k1) && isInt(k2):
+		return v2.Int() >= 0 && v1.Uint() == uint64(v2.Int())
+	}
+	f1, f2 := toFloat(v1), toFloat(v2)
+	return f1 == f2 || (math.IsNaN(f1) && math.IsNaN(f2))
+}
+
+// unwrapInterface returns the value held by a non-nil interface.
+func unwrapInterface(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return v.Elem()
+	}
+	return v
+}
+
+// looseNumbers compares v1 and v2 by numeric value, regardless of their
+// types, if both (or the values held by them as interfaces) are numbers
+// of different types. handled is false otherwise, numbers of the same type
+// are left to the usual comparison, with the float options.
+func looseNumbers(v1, v2 reflect.Value) (handled, equal bool, reason string) {
+	n1, n2 := unwrapInterface(v1), unwrapInterface(v2)
+	if !n1.IsValid() || !n2.IsValid() || !isNumber(n1.Kind()) || !isNumber(n2.Kind()) || n1.Type() == n2.Type() {
+		return false, false, ""
+	}
+	if numbersEqual(n1, n2) {
+		return true, true, ""
+	}
+	return true, false, fmt.Sprintf("numeric values differ: %v != %v", n1, n2)
+}
+
+// signedUnsignedPair tells if one of t1 and t2 is a signed integer type and
+// the other one an unsigned integer type of the same size, for
+// SignedUnsignedEqual.
+func signedUnsignedPair(t1, t2 reflect.Type) bool {
+	k1, k2 := t1.Kind(), t2.Kind()
+	return (isInt(k1) && isUint(k2) || isUint(k1) && isInt(k2)) && t1.Size() == t2.Size()
+}
+
+// compareSignedUnsigned compares a signed and an unsigned integer by value,
+// negative values differ from any unsigned value.
+func compareSignedUnsigned(v1, v2 reflect.Value) (bool, string) {
+	if numbersEqual(v1, v2) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("signed and unsigned values differ: %v != %v", v1, v2)
+}
@@ -0,0 +1,92 @@
+package deepequal
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNumbersEqual(t *testing.T) {
+	tests := []struct {
+		a1   interface{}
+		a2   interface{}
+		want bool
+	}{
+		{a1: 1, a2: int8(1), want: true},
+		{a1: 1, a2: uint(1), want: true},
+		{a1: uint64(math.MaxUint64), a2: -1, want: false},
+		{a1: -1, a2: uint64(math.MaxUint64), want: false},
+		{a1: int64(math.MaxInt64), a2: uint64(math.MaxInt64), want: true},
+		{a1: 2, a2: 2.0, want: true},
+		{a1: 2, a2: 2.5, want: false},
+		{a1: float32(0.5), a2: 0.5, want: true},
+		{a1: math.NaN(), a2: float32(math.NaN()), want: true},
+	}
+	for _, tt := range tests {
+		if got := numbersEqual(reflect.ValueOf(tt.a1), reflect.ValueOf(tt.a2)); got != tt.want {
+			t.Errorf("numbersEqual(%T(%v), %T(%v)) = %v, want %v", tt.a1, tt.a1, tt.a2, tt.a2, got, tt.want)
+		}
+	}
+}
+
+type testSigned struct {
+	Count interface{}
+}
+
+func TestCompareWithOptions_SignedUnsignedEqual(t *testing.T) {
+	opts := Options{SignedUnsignedEqual: true}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "int and uint",
+			a1:   int(5),
+			a2:   uint(5),
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "zero",
+			a1:   int8(0),
+			a2:   uint8(0),
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "max signed",
+			a1:   []interface{}{int64(math.MaxInt64)},
+			a2:   []interface{}{uint64(math.MaxInt64)},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "negative and large unsigned",
+			a1:         testSigned{Count: int8(-1)},
+			a2:         testSigned{Count: uint8(math.MaxUint8)},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Count signed and unsigned values differ: -1 != 255",
+		},
+		{
+			name:       "past max signed",
+			a1:         uint32(math.MaxInt32 + 1),
+			a2:         int32(math.MinInt32),
+			opts:       opts,
+			want:       false,
+			wantReason: "signed and unsigned values differ: 2147483648 != -2147483648",
+		},
+		{
+			name:       "different sizes",
+			a1:         int16(5),
+			a2:         uint32(5),
+			opts:       opts,
+			want:       false,
+			wantReason: "values are of different types: int16 vs uint32",
+		},
+		{
+			name:       "disabled",
+			a1:         int(5),
+			a2:         uint(5),
+			want:       false,
+			wantReason: "values are of different types: int vs uint",
+		},
+	})
+}
@@ -0,0 +1,327 @@
+package deepequal
+
+import (
+	"reflect"
+	"time"
+)
+
+// Options adjusts the comparison performed by CompareWithOptions.
+// The zero value gives the same behaviour as Compare.
+type Options struct {
+	// SkipUnexported skips unexported struct fields instead of failing
+	// with 'struct.NAME unexported' (see CompareS).
+	SkipUnexported bool
+
+	// UseDiffMethod compares values whose type has a method
+	// Diff(T) string (with value or pointer receiver) by calling it.
+	// An empty result means equal, otherwise the result is the reason.
+	UseDiffMethod bool
+
+	// StringerTypes lists types implementing fmt.Stringer (with value or
+	// pointer receiver) which are compared by their String() results.
+	StringerTypes map[reflect.Type]bool
+
+	// IgnoreSliceNil drops the nil check for slices and maps, so they are
+	// compared only by length and contents and a nil slice (or map) equals
+	// an empty one. It applies at any depth, including the elements of
+	// arrays. Arrays themselves are never nil, a nil interface still
+	// differs from an empty slice (see NilInterfaceEqualsEmptySlice).
+	IgnoreSliceNil bool
+
+	// GoSyntax adds the Go-syntax representation of differing scalar
+	// values to the reason: 'scalar values differ: 1 != 2'.
+	GoSyntax bool
+
+	// LooseMapNumerics compares numeric map values (also held in
+	// interfaces, like in map[string]interface{}) by value, regardless of
+	// their types, so int(1) equals float64(1).
+	LooseMapNumerics bool
+
+	// UseCanonicalMethod normalizes values whose type T has a method
+	// Canonical() T (with value or pointer receiver) by calling it on both
+	// sides before comparing.
+	UseCanonicalMethod bool
+
+	// LooseInterfaceNumerics compares numbers held in interfaces by value,
+	// regardless of their dynamic types, so int32(5) equals int64(5).
+	// Other dynamic type mismatches are still reported.
+	LooseInterfaceNumerics bool
+
+	// FuncsByPointer compares non-nil functions by their code pointer
+	// instead of never treating them as equal. Closures created by the
+	// same function literal share the code pointer.
+	FuncsByPointer bool
+
+	// MapPointerKeysByValue matches the keys of maps with pointer keys by
+	// the values they point to instead of by identity. It takes O(n²)
+	// key comparisons.
+	MapPointerKeysByValue bool
+
+	// Subset skips struct fields of the first (expected) value which are
+	// empty, so only the set fields are compared (see CompareSubset).
+	Subset bool
+
+	// EmptyFunc decides if a struct field of the expected value is empty
+	// with Subset, instead of it being the zero value. It gets the field
+	// value, fields which can't be converted with Interface are checked
+	// for the zero value.
+	EmptyFunc func(v interface{}) bool
+
+	// CompareByInterface is an interface type. Values held in interfaces
+	// whose dynamic types implement it are compared by the results of its
+	// methods without arguments, even if the dynamic types differ. Other
+	// methods are ignored. Nil pointers are compared as usual, without
+	// calling their methods.
+	CompareByInterface reflect.Type
+
+	// CompareErrorChain compares errors by unwrapping them with
+	// errors.Unwrap and comparing the type and message of each level.
+	// The reason tells the depth where the chains diverge.
+	CompareErrorChain bool
+
+	// UnorderedSlicesDeep compares slices ignoring the order of elements:
+	// each element of the first slice is matched with an equal element of
+	// the second one, greedily, using the same deep equality. It takes
+	// O(n²) element comparisons.
+	UnorderedSlicesDeep bool
+	// UnorderedEverywhere ignores the order of elements of both slices and
+	// arrays at every level, as UnorderedSlicesDeep does for slices. Maps
+	// are unordered anyway. Element matching uses all other options, like
+	// float tolerance.
+	UnorderedEverywhere bool
+
+	// IgnoreTypes lists types whose values are always treated as equal,
+	// like sync.Mutex or loggers. Struct fields of these types are skipped
+	// even if unexported.
+	IgnoreTypes map[reflect.Type]bool
+	// IgnoreSyncPrimitives treats sync.Mutex, sync.RWMutex, sync.WaitGroup
+	// and sync.Once values (and pointers to them) as equal, like listing
+	// them in IgnoreTypes.
+	IgnoreSyncPrimitives bool
+
+	// UseBinaryMarshaler compares values implementing
+	// encoding.BinaryMarshaler (like time.Time) by their MarshalBinary
+	// results. If it fails, the values are compared as usual.
+	UseBinaryMarshaler bool
+
+	// SliceDivergence reports slices of different lengths by the index
+	// where they diverge and whether the second one looks like the first
+	// one with elements inserted or deleted (by the longest common
+	// subsequence): 'diverge at [2]; lengths 3 vs 4 suggest insertion'.
+	// It takes O(n*m) element comparisons.
+	SliceDivergence bool
+
+	// PublicSurfaceOnly compares structs by their public API only: the
+	// unexported fields are skipped and the results of the exported
+	// methods without arguments with value receiver (called on copies)
+	// are compared instead, as 'Count() ...'. Methods with pointer
+	// receiver aren't called, as they may change the compared values,
+	// like sync.Mutex.TryLock. The methods must be deterministic. Methods
+	// of the results aren't called, which prevents endless recursion
+	// through methods like time.Time.UTC.
+	PublicSurfaceOnly bool
+
+	// MaxSliceElements compares only the first MaxSliceElements elements
+	// of longer slices, ignoring the rest and so the length beyond it.
+	// Slices shorter than that are compared as usual, including their
+	// lengths. Arrays are compared in full.
+	MaxSliceElements int
+
+	// StringBytesInterchangeable compares a string with a byte slice (like
+	// []byte or json.RawMessage) by their contents instead of failing with
+	// a type mismatch, at the top level and in interfaces, like in
+	// map[string]interface{}: 'string and bytes contents differ'.
+	StringBytesInterchangeable bool
+
+	// RuneSlicesAsStrings compares []rune values as strings, reporting
+	// them in full: 'rune slices differ at [2]: "abc" != "abd"'. As rune
+	// is an alias of int32, []int32 values are compared the same way, but
+	// not named slice types.
+	RuneSlicesAsStrings bool
+
+	// StructsByName compares structs of different types (also through
+	// pointers and slices) by their fields with the same names, instead of
+	// failing with a type mismatch. Fields declared only in one of them
+	// differ: 'struct.NAME field missing in second struct'. Methods of such
+	// structs aren't used (see CompareByName).
+	StructsByName bool
+	// RequireFieldOrder additionally requires the same-named fields to be
+	// declared at the same positions with StructsByName, for wire formats
+	// depending on the order: 'struct.NAME field order differs: 0 vs 1'.
+	RequireFieldOrder bool
+
+	// IgnoreMonotonicClock compares time.Time values (which otherwise
+	// differ by their unexported fields) by their wall clock and location,
+	// stripping the monotonic clock readings of time.Now with Round(0):
+	// 'times differ: 2022-05-01 10:00:00 +0000 UTC != ...'. Use
+	// UseBinaryMarshaler to ignore the location too.
+	IgnoreMonotonicClock bool
+
+	// NilInterfaceEqualsEmptySlice treats a nil interface as equal to an
+	// interface holding an empty (nil or not) slice or map of any type,
+	// like a JSON field decoded from null or a missing array and one
+	// decoded from []. It applies to the compared values themselves and to
+	// interfaces inside them, like map[string]interface{} values. A missing
+	// map key still differs from a key with an empty value.
+	NilInterfaceEqualsEmptySlice bool
+
+	// PointerIdentity compares non-nil pointers by address instead of by
+	// the values they point to, so pointers to different elements of the
+	// same array differ even if the elements are equal: 'pointers refer to
+	// different objects'.
+	PointerIdentity bool
+
+	// Parallel compares the fields of the compared structs (also through
+	// pointers) or the elements of the compared slices and arrays in
+	// Parallel goroutines, for large values with many independent parts.
+	// The result is the same as without it. Options with functions (like
+	// EmptyFunc) and methods called by the options must be safe for
+	// concurrent use. It's ignored by the functions reporting all
+	// differences or their paths.
+	Parallel int
+
+	// SkipForeignUnexported is the import path of the caller's package:
+	// unexported fields of the struct types declared in it are compared
+	// (read through unsafe), while those of types from other packages are
+	// skipped, like with SkipUnexported.
+	SkipForeignUnexported string
+
+	// CaseInsensitiveMapKeys matches the keys of maps with string keys
+	// ignoring case, like HTTP headers: '[Accept] key not found ignoring
+	// case'. Maps with keys which are the same ignoring case are ambiguous
+	// and differ.
+	CaseInsensitiveMapKeys bool
+
+	// Base64Bytes compares byte slices at once (with bytes.Equal) and
+	// reports differing ones in base64 with the offset of the first
+	// differing byte: 'bytes differ at offset 2: AQID != AQIE'.
+	Base64Bytes bool
+
+	// SliceKeyField matches the elements of slices of structs (or pointers
+	// to structs) having a comparable field of this name by its value
+	// instead of their position. Elements are reported at their key:
+	// '[42] struct.Name ...', '[42] record only in first slice'. Slices
+	// with duplicate or nil elements, or keys which can't be map keys
+	// (like a slice held in an interface{} field), are compared by
+	// position.
+	SliceKeyField string
+
+	// ProtoEqual compares protobuf messages, values whose type has the
+	// methods Reset(), String() string and ProtoReflect() of generated
+	// messages, like proto.Equal passed here, so the package doesn't
+	// depend on protobuf: 'proto messages differ'.
+	ProtoEqual func(a, b interface{}) bool
+
+	// MaxReasonLen truncates the values formatted into reasons (map keys
+	// in paths, values of unmatched slice elements and of scalars with
+	// GoSyntax) to that many runes, ending them with an ellipsis. Zero
+	// doesn't truncate.
+	MaxReasonLen int
+
+	// TrimNilSliceEnds ignores the elements with the zero value of their
+	// type (nil pointers, interfaces, slices, maps, channels and functions,
+	// zero numbers, empty strings and so on, but not interfaces holding
+	// zero values) at the start and the end of slices, comparing only
+	// what's between them. Indices in reasons are relative to the trimmed
+	// slices. A nil slice still differs from a non-nil one, unless
+	// IgnoreSliceNil is set.
+	TrimNilSliceEnds bool
+
+	// SignedUnsignedEqual compares signed and unsigned integers of the same
+	// size (like int and uint, int32 and uint32) by value instead of
+	// reporting differing types. Negative values differ from any unsigned
+	// value: 'signed and unsigned values differ: -1 != 18446744073709551615'.
+	SignedUnsignedEqual bool
+
+	// NamedComparators holds comparators by name, exported struct fields
+	// tagged with `deepequal:"cmp=NAME"` are compared by the one named
+	// NAME: 'values differ by comparator "ci"'. A name not found here is
+	// reported as 'unknown comparator "ci"'.
+	NamedComparators map[string]func(a, b interface{}) bool
+
+	// LenientPointers compares a pointer with a value of the type it points
+	// to, like a *string field with a string one with StructsByName, by the
+	// value it points to. A nil pointer is an absent value, equal to any
+	// value of that type. A pointer to a different type still differs:
+	// 'values are of differing types'.
+	LenientPointers bool
+
+	// ForceColor makes CompareColorWithOptions color the differences even
+	// if the standard output isn't a terminal.
+	ForceColor bool
+
+	// DeterministicMapOrder compares map values in the order of their keys
+	// (numbers and strings by value, other keys by their formatted form)
+	// instead of the random map order, so the first difference reported is
+	// the same on every run.
+	DeterministicMapOrder bool
+
+	// Timeout limits the time CompareWithOptions takes: it returns false,
+	// 'comparison timed out' once it's exceeded. The comparison runs in a
+	// goroutine which stops at the next value it compares, a function
+	// (like one of NamedComparators) called at the time still runs to its
+	// end. Zero doesn't limit the time.
+	Timeout time.Duration
+
+	// SkipUnexportedIn skips the unexported fields of the listed struct
+	// types only, like SkipUnexported does for all of them.
+	SkipUnexportedIn map[reflect.Type]bool
+
+	// ShapeOnly compares the shape of values, not their data: scalar
+	// values (numbers, strings, bools and the like) are always equal, but
+	// the types, struct fields, slice lengths, map keys and nil pointers,
+	// slices and maps must match. Values compared by hooks, like
+	// bytes.Buffer or with UseDiffMethod, are always equal too.
+	ShapeOnly bool
+
+	// SliceLengthTolerance compares slices whose lengths differ by at most
+	// SliceLengthTolerance over their common prefix, ignoring the extra
+	// tail. It's ignored whichever slice is longer, so the expected
+	// (first) slice may have extra elements too. A nil slice still differs
+	// from a non-nil one, unless IgnoreSliceNil. Arrays are compared in
+	// full.
+	SliceLengthTolerance int
+
+	// StringAliases maps string values to their canonical forms, which
+	// are compared instead, like "US" and "United States" to "USA".
+	// Values not in the map are their own canonical forms. Reasons show
+	// the original values, even without GoSyntax: 'scalar values differ:
+	// "US" != "UK"'. Map keys aren't mapped.
+	StringAliases map[string]string
+
+	// JSONPathPaths starts the reason with the path to the difference in
+	// the syntax of gjson and similar JSON path libraries instead: struct
+	// field names (not JSON names), indexes and map keys joined with
+	// dots, like 'S.2 scalar values differ' for 'struct.S [2] scalar
+	// values differ'. Dots and the other special characters of names and
+	// keys are escaped with a backslash.
+	JSONPathPaths bool
+
+	// UnorderedMapValueSlices compares the slices which are map values
+	// ignoring the order of their elements, like UnorderedSlicesDeep, for
+	// maps of sets like map[string][]string. Other slices, including
+	// those nested in the map values, are compared in order.
+	UnorderedMapValueSlices bool
+
+	// RequirePointerIdentity compares pointers to the listed types (the
+	// types pointed to, like reflect.TypeOf(Conn{})) by address, like
+	// PointerIdentity does for all pointers: pointers to different
+	// objects differ with 'expected same TYPE instance', even if the
+	// objects are equal. Other pointers are compared by the values they
+	// point to.
+	RequirePointerIdentity map[reflect.Type]bool
+
+	// NormalizeNils treats nil and empty the same, for deserialized data:
+	// a nil slice (or map) equals an empty one, like with IgnoreSliceNil,
+	// and a nil pointer to a slice (or map) equals a pointer to a nil or
+	// empty one. A nil interface still differs from an empty slice (see
+	// NilInterfaceEqualsEmptySlice), and nil pointers to other types from
+	// non-nil ones.
+	NormalizeNils bool
+
+	// Float adjusts the comparison of float values.
+	Float FloatOpts
+	// FloatTypes adjusts the comparison of float values of the listed
+	// types (like a named type Celsius float64) instead of Float.
+	FloatTypes map[reflect.Type]FloatOpts
+}
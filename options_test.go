@@ -0,0 +1,1714 @@
+package deepequal
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type optionsTest struct {
+	name       string
+	a1         interface{}
+	a2         interface{}
+	opts       Options
+	want       bool
+	wantReason string
+}
+
+func runOptionsTests(t *testing.T, tests []optionsTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareWithOptions(tt.a1, tt.a2, tt.opts)
+			if got != tt.want {
+				t.Errorf("CompareWithOptions() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareWithOptions() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareWithOptions_IgnoreSliceNil(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "nil and empty slice",
+			a1:   []int(nil),
+			a2:   []int{},
+			opts: Options{IgnoreSliceNil: true},
+			want: true,
+		},
+		{
+			name: "nil and empty map",
+			a1:   map[int]string(nil),
+			a2:   map[int]string{},
+			opts: Options{IgnoreSliceNil: true},
+			want: true,
+		},
+		{
+			name: "nested",
+			a1:   testStruct{Name: "a"},
+			a2:   testStruct{Name: "a", S: []int{}, M: map[int]string{}},
+			opts: Options{IgnoreSliceNil: true},
+			want: true,
+		},
+		{
+			name:       "lengths still differ",
+			a1:         testStruct{Name: "a"},
+			a2:         testStruct{Name: "a", S: []int{1}},
+			opts:       Options{IgnoreSliceNil: true},
+			want:       false,
+			wantReason: "struct.S slices have different lengths",
+		},
+		{
+			name:       "map lengths still differ",
+			a1:         map[int]string(nil),
+			a2:         map[int]string{1: "1"},
+			opts:       Options{IgnoreSliceNil: true},
+			want:       false,
+			wantReason: "maps have different lengths",
+		},
+		{
+			name:       "disabled",
+			a1:         []int(nil),
+			a2:         []int{},
+			want:       false,
+			wantReason: "one slice is nil, the other is not",
+		},
+	})
+}
+
+func TestCompareWithOptions_IgnoreSliceNilArrays(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "array of slices",
+			a1:   [3][]int{nil, {1}, {}},
+			a2:   [3][]int{{}, {1}, nil},
+			opts: Options{IgnoreSliceNil: true},
+			want: true,
+		},
+		{
+			name: "array of maps",
+			a1:   [2]map[string]int{nil, {"a": 1}},
+			a2:   [2]map[string]int{{}, {"a": 1}},
+			opts: Options{IgnoreSliceNil: true},
+			want: true,
+		},
+		{
+			name: "unordered array",
+			a1:   [2][]int{nil, {1}},
+			a2:   [2][]int{{1}, {}},
+			opts: Options{IgnoreSliceNil: true, UnorderedEverywhere: true},
+			want: true,
+		},
+		{
+			name: "interfaces in an array",
+			a1:   [2]interface{}{[]int(nil), map[int]int(nil)},
+			a2:   [2]interface{}{[]int{}, map[int]int{}},
+			opts: Options{IgnoreSliceNil: true},
+			want: true,
+		},
+		{
+			name:       "nil interface in an array",
+			a1:         [1]interface{}{nil},
+			a2:         [1]interface{}{[]int{}},
+			opts:       Options{IgnoreSliceNil: true},
+			want:       false,
+			wantReason: "both interfaces must be nil",
+		},
+		{
+			name: "nil interface in an array with NilInterfaceEqualsEmptySlice",
+			a1:   [1]interface{}{nil},
+			a2:   [1]interface{}{[]int{}},
+			opts: Options{NilInterfaceEqualsEmptySlice: true},
+			want: true,
+		},
+		{
+			name:       "lengths still differ",
+			a1:         [2][]int{nil, nil},
+			a2:         [2][]int{nil, {1}},
+			opts:       Options{IgnoreSliceNil: true},
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+		{
+			name:       "disabled",
+			a1:         [2][]int{nil, {1}},
+			a2:         [2][]int{{}, {1}},
+			want:       false,
+			wantReason: "one slice is nil, the other is not",
+		},
+	})
+}
+
+func TestCompareWithOptions_LooseMapNumerics(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "int and float",
+			a1:   map[string]interface{}{"a": 1, "b": "x"},
+			a2:   map[string]interface{}{"a": 1.0, "b": "x"},
+			opts: Options{LooseMapNumerics: true},
+			want: true,
+		},
+		{
+			name: "nested",
+			a1:   map[string]interface{}{"a": map[string]interface{}{"b": int64(2)}},
+			a2:   map[string]interface{}{"a": map[string]interface{}{"b": uint8(2)}},
+			opts: Options{LooseMapNumerics: true},
+			want: true,
+		},
+		{
+			name: "typed values",
+			a1:   map[string]float32{"a": 1},
+			a2:   map[string]float32{"a": 1},
+			opts: Options{LooseMapNumerics: true},
+			want: true,
+		},
+		{
+			name:       "differ",
+			a1:         map[string]interface{}{"a": 1},
+			a2:         map[string]interface{}{"a": 1.5},
+			opts:       Options{LooseMapNumerics: true},
+			want:       false,
+			wantReason: "[a] numeric values differ: 1 != 1.5",
+		},
+		{
+			name:       "nested differ",
+			a1:         map[string]interface{}{"a": map[string]interface{}{"b": -1}},
+			a2:         map[string]interface{}{"a": map[string]interface{}{"b": uint(1)}},
+			opts:       Options{LooseMapNumerics: true},
+			want:       false,
+			wantReason: "[a] [b] numeric values differ: -1 != 1",
+		},
+		{
+			name: "same type with float tolerance",
+			a1:   map[string]interface{}{"a": 1.0},
+			a2:   map[string]interface{}{"a": 1.001},
+			opts: Options{LooseMapNumerics: true, Float: FloatOpts{Tolerance: 0.01}},
+			want: true,
+		},
+		{
+			name: "typed values with float tolerance",
+			a1:   map[string]float64{"a": 1.0},
+			a2:   map[string]float64{"a": 1.001},
+			opts: Options{LooseMapNumerics: true, Float: FloatOpts{Tolerance: 0.01}},
+			want: true,
+		},
+		{
+			name:       "same type NaN unequal",
+			a1:         map[string]float64{"a": math.NaN()},
+			a2:         map[string]float64{"a": math.NaN()},
+			opts:       Options{LooseMapNumerics: true, Float: FloatOpts{NaNUnequal: true}},
+			want:       false,
+			wantReason: "[a] scalar values differ",
+		},
+		{
+			name: "AnyFloat",
+			a1:   map[string]interface{}{"a": AnyFloat},
+			a2:   map[string]interface{}{"a": 2.0},
+			opts: Options{LooseMapNumerics: true},
+			want: true,
+		},
+		{
+			name: "ShapeOnly",
+			a1:   map[string]int{"a": 1},
+			a2:   map[string]int{"a": 2},
+			opts: Options{LooseMapNumerics: true, ShapeOnly: true},
+			want: true,
+		},
+		{
+			name: "interface numerics with float tolerance",
+			a1:   []interface{}{1.0},
+			a2:   []interface{}{1.001},
+			opts: Options{LooseInterfaceNumerics: true, Float: FloatOpts{Tolerance: 0.01}},
+			want: true,
+		},
+		{
+			name:       "not numeric",
+			a1:         map[string]interface{}{"a": 1},
+			a2:         map[string]interface{}{"a": "1"},
+			opts:       Options{LooseMapNumerics: true},
+			want:       false,
+			wantReason: "[a] values are of differing types: int vs string",
+		},
+		{
+			name:       "disabled",
+			a1:         map[string]interface{}{"a": 1},
+			a2:         map[string]interface{}{"a": 1.0},
+			want:       false,
+			wantReason: "[a] values are of differing types: int vs float64",
+		},
+	})
+}
+
+type testInterfaces struct {
+	Name  string
+	Value interface{}
+	List  []interface{}
+}
+
+func TestCompareWithOptions_LooseInterfaceNumerics(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "top level",
+			a1:   []interface{}{int32(5)},
+			a2:   []interface{}{int64(5)},
+			opts: Options{LooseInterfaceNumerics: true},
+			want: true,
+		},
+		{
+			name: "struct fields",
+			a1:   testInterfaces{Value: uint16(7), List: []interface{}{1, 2.5}},
+			a2:   testInterfaces{Value: 7.0, List: []interface{}{int8(1), float32(2.5)}},
+			opts: Options{LooseInterfaceNumerics: true},
+			want: true,
+		},
+		{
+			name:       "differ",
+			a1:         testInterfaces{Value: int32(5)},
+			a2:         testInterfaces{Value: int64(6)},
+			opts:       Options{LooseInterfaceNumerics: true},
+			want:       false,
+			wantReason: "struct.Value numeric values differ: 5 != 6",
+		},
+		{
+			name:       "non-numeric types",
+			a1:         testInterfaces{Value: 5},
+			a2:         testInterfaces{Value: "5"},
+			opts:       Options{LooseInterfaceNumerics: true},
+			want:       false,
+			wantReason: "struct.Value values are of differing types: int vs string",
+		},
+		{
+			name:       "non-interface fields",
+			a1:         testInterfaces{Name: "a", Value: 5},
+			a2:         testInterfaces{Name: "b", Value: 5.0},
+			opts:       Options{LooseInterfaceNumerics: true},
+			want:       false,
+			wantReason: "struct.Name scalar values differ",
+		},
+		{
+			name:       "disabled",
+			a1:         testInterfaces{Value: int32(5)},
+			a2:         testInterfaces{Value: int64(5)},
+			want:       false,
+			wantReason: "struct.Value values are of differing types: int32 vs int64",
+		},
+	})
+}
+
+type testHandlers struct {
+	Name    string
+	Handler func() string
+	OnClose func()
+}
+
+func testHandlerA() string { return "a" }
+
+func testHandlerB() string { return "b" }
+
+func TestCompareWithOptions_FuncsByPointer(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name:       "default",
+			a1:         testHandlers{Handler: testHandlerA},
+			a2:         testHandlers{Handler: testHandlerA},
+			want:       false,
+			wantReason: "struct.Handler non-nil functions never compare equal",
+		},
+		{
+			name: "default nil",
+			a1:   testHandlers{},
+			a2:   testHandlers{},
+			want: true,
+		},
+		{
+			name: "same function",
+			a1:   testHandlers{Handler: testHandlerA},
+			a2:   testHandlers{Handler: testHandlerA},
+			opts: Options{FuncsByPointer: true},
+			want: true,
+		},
+		{
+			name:       "different functions",
+			a1:         testHandlers{Handler: testHandlerA},
+			a2:         testHandlers{Handler: testHandlerB},
+			opts:       Options{FuncsByPointer: true},
+			want:       false,
+			wantReason: "struct.Handler functions differ",
+		},
+		{
+			name:       "one nil",
+			a1:         testHandlers{Handler: testHandlerA, OnClose: func() {}},
+			a2:         testHandlers{Handler: testHandlerA},
+			opts:       Options{FuncsByPointer: true},
+			want:       false,
+			wantReason: "struct.OnClose functions differ",
+		},
+	})
+}
+
+type testKey struct {
+	ID   int
+	Zone string
+}
+
+func TestCompareWithOptions_MapPointerKeysByValue(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "equal",
+			a1:   map[*testKey]string{{1, "a"}: "x", {2, "a"}: "y"},
+			a2:   map[*testKey]string{{2, "a"}: "y", {1, "a"}: "x"},
+			opts: Options{MapPointerKeysByValue: true},
+			want: true,
+		},
+		{
+			name:       "value differ",
+			a1:         map[*testKey]string{{1, "a"}: "x"},
+			a2:         map[*testKey]string{{1, "a"}: "y"},
+			opts:       Options{MapPointerKeysByValue: true},
+			want:       false,
+			wantReason: "[{ID:1 Zone:a}] scalar values differ",
+		},
+		{
+			name:       "unmatched key",
+			a1:         map[*testKey]string{{1, "a"}: "x"},
+			a2:         map[*testKey]string{{1, "b"}: "x"},
+			opts:       Options{MapPointerKeysByValue: true},
+			want:       false,
+			wantReason: "[{ID:1 Zone:a}] key not found",
+		},
+		{
+			name: "duplicate key values",
+			a1:   map[*testKey]int{{1, "a"}: 1, {1, "a"}: 1},
+			a2:   map[*testKey]int{{1, "a"}: 1, {1, "a"}: 1},
+			opts: Options{MapPointerKeysByValue: true},
+			want: true,
+		},
+		{
+			name:       "duplicate key values unmatched",
+			a1:         map[*testKey]int{{1, "a"}: 1, {1, "a"}: 1},
+			a2:         map[*testKey]int{{1, "a"}: 1, {2, "a"}: 1},
+			opts:       Options{MapPointerKeysByValue: true},
+			want:       false,
+			wantReason: "[{ID:1 Zone:a}] key not found",
+		},
+		{
+			name: "nested",
+			a1:   []map[*testKey][]int{{{1, "a"}: {1}}},
+			a2:   []map[*testKey][]int{{{1, "a"}: {1}}},
+			opts: Options{MapPointerKeysByValue: true},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         map[*testKey]string{{1, "a"}: "x"},
+			a2:         map[*testKey]string{{1, "a"}: "x"},
+			want:       false,
+			wantReason: "[&{ID:1 Zone:a}] invalid values are not equal",
+		},
+	})
+}
+
+type testSharedKey struct {
+	Key *testKey
+	N   int
+}
+
+func TestCompareWithOptions_MapPointerKeysByValue_SharedPointers(t *testing.T) {
+	// a failed attempt to match a key must not make the keys shared by
+	// the next attempt equal
+	p, q := &testKey{1, "a"}, &testKey{2, "a"}
+	a1 := map[*testSharedKey]int{{p, 1}: 1, {p, 2}: 1}
+	a2 := map[*testSharedKey]int{{q, 1}: 1, {q, 2}: 1}
+	for i := 0; i < 20; i++ {
+		if got, _ := CompareWithOptions(a1, a2, Options{MapPointerKeysByValue: true}); got {
+			t.Fatalf("CompareWithOptions() got = %v, want false", got)
+		}
+	}
+}
+
+type testNullString struct {
+	String string
+	Valid  bool
+}
+
+type testRecord struct {
+	Name  string
+	Count int
+	Note  testNullString
+	Tags  []string
+	Inner *testRecord
+}
+
+func TestCompareSubset(t *testing.T) {
+	tests := []struct {
+		name       string
+		expected   interface{}
+		actual     interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name:     "unset fields",
+			expected: testRecord{Name: "a"},
+			actual:   testRecord{Name: "a", Count: 2, Tags: []string{"x"}},
+			want:     true,
+		},
+		{
+			name:     "nested",
+			expected: testRecord{Inner: &testRecord{Count: 1}},
+			actual:   testRecord{Name: "a", Inner: &testRecord{Name: "b", Count: 1}},
+			want:     true,
+		},
+		{
+			name:       "set field differ",
+			expected:   testRecord{Name: "a", Count: 1},
+			actual:     testRecord{Name: "a", Count: 2},
+			want:       false,
+			wantReason: "struct.Count scalar values differ",
+		},
+		{
+			name:       "nested differ",
+			expected:   testRecord{Inner: &testRecord{Count: 1}},
+			actual:     testRecord{Inner: &testRecord{Count: 2}},
+			want:       false,
+			wantReason: "struct.Inner struct.Count scalar values differ",
+		},
+		{
+			name:       "slice elements are compared",
+			expected:   testRecord{Tags: []string{"x", ""}},
+			actual:     testRecord{Tags: []string{"x", "y"}},
+			want:       false,
+			wantReason: "struct.Tags [1] scalar values differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareSubset(tt.expected, tt.actual)
+			if got != tt.want {
+				t.Errorf("CompareSubset() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareSubset() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+type testSchemaLimits struct {
+	CPU, Memory int
+}
+
+type testSchemaService struct {
+	Name   string
+	Limits testSchemaLimits
+	Labels map[string]string
+	Extra  map[string]interface{}
+	Backup *testRecord
+}
+
+func TestCompareSchema(t *testing.T) {
+	actual := testSchemaService{
+		Name:   "api",
+		Limits: testSchemaLimits{CPU: 2, Memory: 512},
+		Labels: map[string]string{"team": "core", "tier": "web"},
+		Extra:  map[string]interface{}{"replicas": 3, "zone": map[string]interface{}{"name": "a", "id": 1}},
+		Backup: &testRecord{Name: "b", Count: 1},
+	}
+	tests := []struct {
+		name       string
+		reference  interface{}
+		actual     interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name:      "nested reference",
+			reference: testSchemaService{Limits: testSchemaLimits{CPU: 2}, Labels: map[string]string{"team": "core"}},
+			actual:    actual,
+			want:      true,
+		},
+		{
+			name:      "zero nested struct and nil pointer skipped",
+			reference: testSchemaService{Name: "api"},
+			actual:    actual,
+			want:      true,
+		},
+		{
+			name:      "zero map values skipped",
+			reference: testSchemaService{Labels: map[string]string{"team": "", "tier": "web", "other": ""}},
+			actual:    actual,
+			want:      true,
+		},
+		{
+			name:      "maps in interfaces",
+			reference: map[string]interface{}{"Extra": map[string]interface{}{"zone": map[string]interface{}{"id": 1}}},
+			actual:    map[string]interface{}{"Name": "api", "Extra": actual.Extra},
+			want:      true,
+		},
+		{
+			name:       "nested field differs",
+			reference:  testSchemaService{Limits: testSchemaLimits{Memory: 256}},
+			actual:     actual,
+			want:       false,
+			wantReason: "struct.Limits struct.Memory scalar values differ",
+		},
+		{
+			name:       "key missing",
+			reference:  testSchemaService{Labels: map[string]string{"owner": "x", "team": "core"}},
+			actual:     actual,
+			want:       false,
+			wantReason: "struct.Labels [owner] key missing in actual",
+		},
+		{
+			name:       "pointer target compared",
+			reference:  testSchemaService{Backup: &testRecord{Count: 2}},
+			actual:     actual,
+			want:       false,
+			wantReason: "struct.Backup struct.Count scalar values differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareSchema(tt.reference, tt.actual)
+			if got != tt.want {
+				t.Errorf("CompareSchema() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareSchema() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareWithOptions_EmptyFunc(t *testing.T) {
+	// a null string is empty even if it has a leftover value
+	empty := func(v interface{}) bool {
+		if ns, ok := v.(testNullString); ok {
+			return !ns.Valid
+		}
+		return reflect.ValueOf(v).IsZero()
+	}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "invalid is empty",
+			a1:   testRecord{Name: "a", Note: testNullString{String: "old"}},
+			a2:   testRecord{Name: "a", Note: testNullString{String: "new", Valid: true}},
+			opts: Options{Subset: true, EmptyFunc: empty},
+			want: true,
+		},
+		{
+			name:       "valid is compared",
+			a1:         testRecord{Name: "a", Note: testNullString{String: "old", Valid: true}},
+			a2:         testRecord{Name: "a", Note: testNullString{String: "new", Valid: true}},
+			opts:       Options{Subset: true, EmptyFunc: empty},
+			want:       false,
+			wantReason: "struct.Note struct.String scalar values differ",
+		},
+		{
+			name:       "zero value without EmptyFunc",
+			a1:         testRecord{Name: "a", Note: testNullString{String: "old"}},
+			a2:         testRecord{Name: "a", Note: testNullString{String: "new", Valid: true}},
+			opts:       Options{Subset: true},
+			want:       false,
+			wantReason: "struct.Note struct.String scalar values differ",
+		},
+		{
+			name:       "other fields",
+			a1:         testRecord{Name: "a"},
+			a2:         testRecord{Name: "b"},
+			opts:       Options{Subset: true, EmptyFunc: empty},
+			want:       false,
+			wantReason: "struct.Name scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_UnorderedSlicesDeep(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "reordered",
+			a1:   []int{1, 2, 3},
+			a2:   []int{3, 1, 2},
+			opts: Options{UnorderedSlicesDeep: true},
+			want: true,
+		},
+		{
+			name: "duplicates",
+			a1:   []int{1, 1, 2},
+			a2:   []int{1, 2, 1},
+			opts: Options{UnorderedSlicesDeep: true},
+			want: true,
+		},
+		{
+			name:       "duplicates differ",
+			a1:         []int{1, 1, 2},
+			a2:         []int{1, 2, 2},
+			opts:       Options{UnorderedSlicesDeep: true},
+			want:       false,
+			wantReason: "[1] unmatched element 1",
+		},
+		{
+			name: "structs",
+			a1:   []testStruct{{Name: "a", S: []int{1, 2}}, {Name: "b"}},
+			a2:   []testStruct{{Name: "b"}, {Name: "a", S: []int{2, 1}}},
+			opts: Options{UnorderedSlicesDeep: true},
+			want: true,
+		},
+		{
+			name:       "structs differ",
+			a1:         []testStruct{{Name: "a", S: []int{1, 2}}, {Name: "b"}},
+			a2:         []testStruct{{Name: "b"}, {Name: "a", S: []int{2, 3}}},
+			opts:       Options{UnorderedSlicesDeep: true},
+			want:       false,
+			wantReason: "[0] unmatched element {Name:a S:[1 2] M:map[]}",
+		},
+		{
+			name: "shared pointers",
+			a1:   []*testNode{{V: 1}, {V: 2}},
+			a2:   []*testNode{{V: 2}, {V: 1}},
+			opts: Options{UnorderedSlicesDeep: true},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         []int{1, 2, 3},
+			a2:         []int{3, 1, 2},
+			want:       false,
+			wantReason: "[0] scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_UnorderedSlicesDeepVisited(t *testing.T) {
+	// a failed attempt to match p with q must not make them equal later
+	p := &testNode{V: 1}
+	q := &testNode{V: 2}
+	a1 := []interface{}{[]*testNode{p, q}, p}
+	a2 := []interface{}{[]*testNode{q, p}, q}
+	if equal, _ := CompareWithOptions(a1, a2, Options{UnorderedSlicesDeep: true}); equal {
+		t.Errorf("CompareWithOptions() got = %v, want false", equal)
+	}
+}
+
+type testConfig struct {
+	Hosts   []string
+	Ports   [3]int
+	Weights []float64
+	Groups  map[string][][]string
+}
+
+func TestCompareWithOptions_UnorderedEverywhere(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "nested",
+			a1: testConfig{
+				Hosts:   []string{"a", "b"},
+				Ports:   [3]int{80, 443, 8080},
+				Weights: []float64{0.5, 1},
+				Groups:  map[string][][]string{"g": {{"x", "y"}, {"z"}}},
+			},
+			a2: testConfig{
+				Hosts:   []string{"b", "a"},
+				Ports:   [3]int{8080, 80, 443},
+				Weights: []float64{1, 0.5},
+				Groups:  map[string][][]string{"g": {{"z"}, {"y", "x"}}},
+			},
+			opts: Options{UnorderedEverywhere: true},
+			want: true,
+		},
+		{
+			name:       "nested differ",
+			a1:         testConfig{Groups: map[string][][]string{"g": {{"x", "y"}, {"z"}}}},
+			a2:         testConfig{Groups: map[string][][]string{"g": {{"z"}, {"y", "w"}}}},
+			opts:       Options{UnorderedEverywhere: true},
+			want:       false,
+			wantReason: "struct.Groups [g] [0] unmatched element [x y]",
+		},
+		{
+			name: "with tolerance",
+			a1:   testConfig{Weights: []float64{0.5, 1}},
+			a2:   testConfig{Weights: []float64{1.01, 0.49}},
+			opts: Options{UnorderedEverywhere: true, Float: FloatOpts{Tolerance: 0.05}},
+			want: true,
+		},
+		{
+			name:       "arrays only with UnorderedEverywhere",
+			a1:         testConfig{Ports: [3]int{80, 443, 8080}},
+			a2:         testConfig{Ports: [3]int{8080, 80, 443}},
+			opts:       Options{UnorderedSlicesDeep: true},
+			want:       false,
+			wantReason: "struct.Ports scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_MaxSliceElements(t *testing.T) {
+	long1 := make([]int, 1000)
+	long2 := make([]int, 1200)
+	long2[999] = 1
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "differ beyond the cap",
+			a1:   long1,
+			a2:   long2,
+			opts: Options{MaxSliceElements: 100},
+			want: true,
+		},
+		{
+			name:       "differ within the cap",
+			a1:         long1,
+			a2:         long2,
+			opts:       Options{MaxSliceElements: 1000},
+			want:       false,
+			wantReason: "[999] scalar values differ",
+		},
+		{
+			name:       "shorter than the cap",
+			a1:         []int{1, 2},
+			a2:         []int{1, 2, 3},
+			opts:       Options{MaxSliceElements: 5},
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+		{
+			name: "nested",
+			a1:   testStruct{S: []int{1, 2, 3}},
+			a2:   testStruct{S: []int{1, 2, 4, 5}},
+			opts: Options{MaxSliceElements: 2},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         long1,
+			a2:         long2,
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+	})
+}
+
+type testLocked struct {
+	mu    sync.Mutex
+	Lock  *sync.RWMutex
+	Name  string
+	Items []int
+}
+
+type testLogger struct {
+	prefix string
+}
+
+type testService struct {
+	Name    string
+	Loggers []*testLogger
+}
+
+func TestCompareWithOptions_IgnoreTypes(t *testing.T) {
+	ignore := map[reflect.Type]bool{
+		reflect.TypeOf(sync.Mutex{}):    true,
+		reflect.TypeOf(&sync.RWMutex{}): true,
+		reflect.TypeOf(&testLogger{}):   true,
+	}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "mutex fields",
+			a1:   &testLocked{Lock: &sync.RWMutex{}, Name: "a", Items: []int{1}},
+			a2:   &testLocked{Name: "a", Items: []int{1}},
+			opts: Options{IgnoreTypes: ignore},
+			want: true,
+		},
+		{
+			name:       "other fields are compared",
+			a1:         &testLocked{Name: "a", Items: []int{1}},
+			a2:         &testLocked{Name: "a", Items: []int{2}},
+			opts:       Options{IgnoreTypes: ignore},
+			want:       false,
+			wantReason: "struct.Items [0] scalar values differ",
+		},
+		{
+			name: "slice elements",
+			a1:   testService{Name: "a", Loggers: []*testLogger{{prefix: "x"}}},
+			a2:   testService{Name: "a", Loggers: []*testLogger{{prefix: "y"}}},
+			opts: Options{IgnoreTypes: ignore},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         &testLocked{Name: "a"},
+			a2:         &testLocked{Name: "a"},
+			want:       false,
+			wantReason: "struct.mu unexported",
+		},
+	})
+}
+
+type testCache struct {
+	sync.Mutex
+	rw    sync.RWMutex
+	wg    *sync.WaitGroup
+	once  sync.Once
+	Data  map[string]int
+	Count int
+}
+
+func TestCompareWithOptions_IgnoreSyncPrimitives(t *testing.T) {
+	locked := &testCache{wg: &sync.WaitGroup{}, Data: map[string]int{"a": 1}}
+	locked.Lock()
+	defer locked.Unlock()
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "locked and unlocked",
+			a1:   locked,
+			a2:   &testCache{Data: map[string]int{"a": 1}},
+			opts: Options{IgnoreSyncPrimitives: true},
+			want: true,
+		},
+		{
+			name:       "data differ",
+			a1:         locked,
+			a2:         &testCache{Data: map[string]int{"a": 2}},
+			opts:       Options{IgnoreSyncPrimitives: true},
+			want:       false,
+			wantReason: "struct.Data [a] scalar values differ",
+		},
+		{
+			name: "unexported mutex",
+			a1:   &testLocked{Name: "a"},
+			a2:   &testLocked{Name: "a"},
+			opts: Options{IgnoreSyncPrimitives: true},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         &testLocked{Name: "a"},
+			a2:         &testLocked{Name: "a"},
+			want:       false,
+			wantReason: "struct.mu unexported",
+		},
+	})
+}
+
+func TestCompareWithOptions_SliceDivergence(t *testing.T) {
+	large := make([]int, 50000)
+	for i := range large {
+		large[i] = i
+	}
+	shorter := append(append([]int{}, large[:20000]...), large[20001:]...)
+	runOptionsTests(t, []optionsTest{
+		{
+			name:       "insertion",
+			a1:         testStruct{S: []int{1, 2, 3}},
+			a2:         testStruct{S: []int{1, 2, 9, 3}},
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "struct.S diverge at [2]; lengths 3 vs 4 suggest insertion",
+		},
+		{
+			name:       "appended",
+			a1:         []string{"a", "b"},
+			a2:         []string{"a", "b", "c"},
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "diverge at [2]; lengths 2 vs 3 suggest insertion",
+		},
+		{
+			name:       "deletion",
+			a1:         []int{1, 2, 3, 4},
+			a2:         []int{2, 3, 4},
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "diverge at [0]; lengths 4 vs 3 suggest deletion",
+		},
+		{
+			name:       "changes",
+			a1:         []int{1, 2, 3},
+			a2:         []int{1, 5, 3, 4},
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "diverge at [1]; lengths 3 vs 4 suggest changes",
+		},
+		{
+			name:       "structs",
+			a1:         []testStruct{{Name: "a"}, {Name: "b"}},
+			a2:         []testStruct{{Name: "a"}, {Name: "x"}, {Name: "b"}},
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "diverge at [1]; lengths 2 vs 3 suggest insertion",
+		},
+		{
+			name:       "changes between common ends",
+			a1:         []int{1, 2, 3, 4, 5},
+			a2:         []int{1, 7, 3, 8, 4, 5},
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "diverge at [1]; lengths 5 vs 6 suggest changes",
+		},
+		{
+			name:       "repeated elements",
+			a1:         []int{1, 1},
+			a2:         []int{1, 1, 1},
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "diverge at [2]; lengths 2 vs 3 suggest insertion",
+		},
+		{
+			name:       "large slices",
+			a1:         large,
+			a2:         shorter,
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "diverge at [20000]; lengths 50000 vs 49999 suggest deletion",
+		},
+		{
+			name:       "same lengths",
+			a1:         []int{1, 2, 3},
+			a2:         []int{1, 5, 3},
+			opts:       Options{SliceDivergence: true},
+			want:       false,
+			wantReason: "[1] scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_StringBytesInterchangeable(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "top level",
+			a1:   "abc",
+			a2:   []byte("abc"),
+			opts: Options{StringBytesInterchangeable: true},
+			want: true,
+		},
+		{
+			name:       "top level differ",
+			a1:         []byte("abc"),
+			a2:         "abd",
+			opts:       Options{StringBytesInterchangeable: true},
+			want:       false,
+			wantReason: "string and bytes contents differ",
+		},
+		{
+			name: "nested",
+			a1:   map[string]interface{}{"raw": []byte(`{"a":1}`), "n": 1},
+			a2:   map[string]interface{}{"raw": `{"a":1}`, "n": 1},
+			opts: Options{StringBytesInterchangeable: true},
+			want: true,
+		},
+		{
+			name:       "nested differ",
+			a1:         []interface{}{"x", "y"},
+			a2:         []interface{}{"x", []byte("z")},
+			opts:       Options{StringBytesInterchangeable: true},
+			want:       false,
+			wantReason: "[1] string and bytes contents differ",
+		},
+		{
+			name: "empty string and nil bytes",
+			a1:   "",
+			a2:   []byte(nil),
+			opts: Options{StringBytesInterchangeable: true},
+			want: true,
+		},
+		{
+			name:       "other types",
+			a1:         []interface{}{"1"},
+			a2:         []interface{}{1},
+			opts:       Options{StringBytesInterchangeable: true},
+			want:       false,
+			wantReason: "[0] values are of differing types: string vs int",
+		},
+		{
+			name:       "disabled",
+			a1:         "abc",
+			a2:         []byte("abc"),
+			want:       false,
+			wantReason: "values are of different types: string vs []uint8",
+		},
+	})
+}
+
+func TestCompareWithOptions_NilInterfaceEqualsEmptySlice(t *testing.T) {
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "missing and empty array",
+			a1:   map[string]interface{}{"items": nil, "n": 1.0},
+			a2:   map[string]interface{}{"items": []interface{}{}, "n": 1.0},
+			opts: Options{NilInterfaceEqualsEmptySlice: true},
+			want: true,
+		},
+		{
+			name: "empty map first",
+			a1:   []interface{}{map[string]interface{}{}},
+			a2:   []interface{}{nil},
+			opts: Options{NilInterfaceEqualsEmptySlice: true},
+			want: true,
+		},
+		{
+			name: "struct field",
+			a1:   testEnvelope{Payload: nil},
+			a2:   testEnvelope{Payload: []int(nil)},
+			opts: Options{NilInterfaceEqualsEmptySlice: true},
+			want: true,
+		},
+		{
+			name: "top level",
+			a1:   nil,
+			a2:   []string{},
+			opts: Options{NilInterfaceEqualsEmptySlice: true},
+			want: true,
+		},
+		{
+			name:       "non-empty slice",
+			a1:         testEnvelope{Payload: nil},
+			a2:         testEnvelope{Payload: []int{1}},
+			opts:       Options{NilInterfaceEqualsEmptySlice: true},
+			want:       false,
+			wantReason: "struct.Payload both interfaces must be nil",
+		},
+		{
+			name:       "other value",
+			a1:         testEnvelope{Payload: ""},
+			a2:         testEnvelope{Payload: nil},
+			opts:       Options{NilInterfaceEqualsEmptySlice: true},
+			want:       false,
+			wantReason: "struct.Payload both interfaces must be nil",
+		},
+		{
+			name:       "missing key",
+			a1:         map[string]interface{}{"a": 1.0},
+			a2:         map[string]interface{}{"a": 1.0, "items": []interface{}{}},
+			opts:       Options{NilInterfaceEqualsEmptySlice: true},
+			want:       false,
+			wantReason: "maps have different lengths",
+		},
+		{
+			name:       "disabled",
+			a1:         map[string]interface{}{"items": nil},
+			a2:         map[string]interface{}{"items": []interface{}{}},
+			want:       false,
+			wantReason: "[items] both interfaces must be nil",
+		},
+	})
+}
+
+func TestCompareWithOptions_PointerIdentity(t *testing.T) {
+	arr := [3]int{1, 1, 1}
+	node := &testNode{V: 1}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "same element",
+			a1:   &arr[0],
+			a2:   &arr[0],
+			opts: Options{PointerIdentity: true},
+			want: true,
+		},
+		{
+			name:       "elements of the same array",
+			a1:         []*int{&arr[0], &arr[1]},
+			a2:         []*int{&arr[0], &arr[2]},
+			opts:       Options{PointerIdentity: true},
+			want:       false,
+			wantReason: "[1] pointers refer to different objects",
+		},
+		{
+			name:       "equal copies",
+			a1:         testNode{V: 1, Next: node},
+			a2:         testNode{V: 1, Next: &testNode{V: 1}},
+			opts:       Options{PointerIdentity: true},
+			want:       false,
+			wantReason: "struct.Next pointers refer to different objects",
+		},
+		{
+			name:       "nil",
+			a1:         testNode{Next: node},
+			a2:         testNode{},
+			opts:       Options{PointerIdentity: true},
+			want:       false,
+			wantReason: "struct.Next one pointer is nil, the other is not",
+		},
+		{
+			name: "disabled",
+			a1:   []*int{&arr[0], &arr[1]},
+			a2:   []*int{&arr[0], &arr[2]},
+			want: true,
+		},
+	})
+}
+
+type testLocalState struct {
+	Name  string
+	count int
+	inner *testLocalState
+	at    time.Time
+}
+
+func TestCompareWithOptions_SkipForeignUnexported(t *testing.T) {
+	const pkg = "github.com/msaf1980/deepequal"
+	now := time.Now()
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "equal",
+			a1:   testLocalState{Name: "a", count: 1, inner: &testLocalState{count: 2}},
+			a2:   testLocalState{Name: "a", count: 1, inner: &testLocalState{count: 2}},
+			opts: Options{SkipForeignUnexported: pkg},
+			want: true,
+		},
+		{
+			name:       "local unexported differ",
+			a1:         testLocalState{Name: "a", count: 1},
+			a2:         testLocalState{Name: "a", count: 2},
+			opts:       Options{SkipForeignUnexported: pkg},
+			want:       false,
+			wantReason: "struct.count scalar values differ",
+		},
+		{
+			name:       "nested local unexported differ",
+			a1:         &testLocalState{inner: &testLocalState{count: 2}},
+			a2:         &testLocalState{inner: &testLocalState{count: 3}},
+			opts:       Options{SkipForeignUnexported: pkg},
+			want:       false,
+			wantReason: "struct.inner struct.count scalar values differ",
+		},
+		{
+			name: "foreign unexported skipped",
+			a1:   []testLocalState{{at: now}},
+			a2:   []testLocalState{{at: now.Round(0)}},
+			opts: Options{SkipForeignUnexported: pkg},
+			want: true,
+		},
+		{
+			name: "other package",
+			a1:   testLocalState{count: 1},
+			a2:   testLocalState{count: 2},
+			opts: Options{SkipForeignUnexported: "example.com/other"},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         testLocalState{count: 1},
+			a2:         testLocalState{count: 1},
+			want:       false,
+			wantReason: "struct.count unexported",
+		},
+	})
+}
+
+func TestCompareWithOptions_MaxReasonLen(t *testing.T) {
+	huge := strings.Repeat("k", 1000)
+	runOptionsTests(t, []optionsTest{
+		{
+			name:       "huge map key",
+			a1:         map[string]int{huge: 1},
+			a2:         map[string]int{huge: 2},
+			opts:       Options{MaxReasonLen: 10},
+			want:       false,
+			wantReason: "[kkkkkkkkk…] scalar values differ",
+		},
+		{
+			name:       "nested map key",
+			a1:         []map[string][]int{{huge: {1}}},
+			a2:         []map[string][]int{{huge: {2}}},
+			opts:       Options{MaxReasonLen: 5},
+			want:       false,
+			wantReason: "[0] [kkkk…] [0] scalar values differ",
+		},
+		{
+			name:       "short key",
+			a1:         map[string]int{"key": 1},
+			a2:         map[string]int{"key": 2},
+			opts:       Options{MaxReasonLen: 3},
+			want:       false,
+			wantReason: "[key] scalar values differ",
+		},
+		{
+			name:       "go syntax",
+			a1:         huge,
+			a2:         huge + "!",
+			opts:       Options{MaxReasonLen: 6, GoSyntax: true},
+			want:       false,
+			wantReason: `scalar values differ: "kkkk… != "kkkk…`,
+		},
+		{
+			name:       "unmatched element",
+			a1:         []string{huge},
+			a2:         []string{"x"},
+			opts:       Options{MaxReasonLen: 4, UnorderedSlicesDeep: true},
+			want:       false,
+			wantReason: "[0] unmatched element kkk…",
+		},
+		{
+			name:       "disabled",
+			a1:         map[string]int{huge[:20]: 1},
+			a2:         map[string]int{huge[:20]: 2},
+			want:       false,
+			wantReason: "[kkkkkkkkkkkkkkkkkkkk] scalar values differ",
+		},
+	})
+}
+
+type testOpaque struct {
+	ID    int
+	cache map[string]int
+}
+
+type testHolder struct {
+	Opaque testOpaque
+	Local  testLocalState
+}
+
+func TestCompareWithOptions_SkipUnexportedIn(t *testing.T) {
+	opts := Options{SkipUnexportedIn: map[reflect.Type]bool{reflect.TypeOf(testOpaque{}): true}}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "listed type",
+			a1:   testOpaque{ID: 1, cache: map[string]int{"a": 1}},
+			a2:   testOpaque{ID: 1},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "exported fields of listed type compared",
+			a1:         []testOpaque{{ID: 1}},
+			a2:         []testOpaque{{ID: 2}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[0] struct.ID scalar values differ",
+		},
+		{
+			name:       "other types",
+			a1:         testHolder{Opaque: testOpaque{cache: map[string]int{}}, Local: testLocalState{count: 1}},
+			a2:         testHolder{Opaque: testOpaque{}, Local: testLocalState{count: 1}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Local struct.count unexported",
+		},
+		{
+			name: "several types",
+			a1:   testHolder{Local: testLocalState{Name: "a"}},
+			a2:   testHolder{Local: testLocalState{Name: "a", at: time.Now()}},
+			opts: Options{SkipUnexportedIn: map[reflect.Type]bool{
+				reflect.TypeOf(testLocalState{}): true,
+				reflect.TypeOf(testOpaque{}):     true,
+			}},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         testOpaque{ID: 1},
+			a2:         testOpaque{ID: 1},
+			want:       false,
+			wantReason: "struct.cache unexported",
+		},
+	})
+}
+
+type testShape struct {
+	Name   string
+	Ratio  float64
+	Tags   []string
+	Limits map[string]int
+	Next   *testShape
+}
+
+func TestCompareWithOptions_ShapeOnly(t *testing.T) {
+	opts := Options{ShapeOnly: true}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "scalar values ignored",
+			a1:   testShape{Name: "a", Ratio: 0.5, Tags: []string{"x"}, Limits: map[string]int{"cpu": 1}},
+			a2:   testShape{Name: "b", Ratio: 2, Tags: []string{"y"}, Limits: map[string]int{"cpu": 4}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "slice lengths",
+			a1:         testShape{Tags: []string{"x"}},
+			a2:         testShape{Tags: []string{"x", "y"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Tags slices have different lengths",
+		},
+		{
+			name:       "map keys",
+			a1:         testShape{Limits: map[string]int{"cpu": 1}},
+			a2:         testShape{Limits: map[string]int{"mem": 1}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Limits [cpu] invalid values are not equal",
+		},
+		{
+			name:       "nil pointer",
+			a1:         testShape{Next: &testShape{}},
+			a2:         testShape{},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Next one pointer is nil, the other is not",
+		},
+		{
+			name: "nested",
+			a1:   testShape{Next: &testShape{Name: "a", Tags: []string{}}},
+			a2:   testShape{Next: &testShape{Name: "b", Tags: []string{}}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "types",
+			a1:         []interface{}{1},
+			a2:         []interface{}{"1"},
+			opts:       opts,
+			want:       false,
+			wantReason: "[0] values are of differing types: int vs string",
+		},
+		{
+			name: "type comparers",
+			a1:   newTestBuffers("abc", "def"),
+			a2:   newTestBuffers("xyz", "uvw"),
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "Diff method",
+			a1:   testMoney{Amount: 1, Currency: "USD"},
+			a2:   testMoney{Amount: 2, Currency: "EUR"},
+			opts: Options{ShapeOnly: true, UseDiffMethod: true},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         testShape{Name: "a"},
+			a2:         testShape{Name: "b"},
+			want:       false,
+			wantReason: "struct.Name scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_SliceLengthTolerance(t *testing.T) {
+	opts := Options{SliceLengthTolerance: 1}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "longer second slice",
+			a1:   []float64{1, 2, 3},
+			a2:   []float64{1, 2, 3, 4},
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "longer first slice",
+			a1:   []float64{1, 2, 3, 4},
+			a2:   []float64{1, 2, 3},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "difference in common prefix",
+			a1:         []float64{1, 5, 3},
+			a2:         []float64{1, 2, 3, 4},
+			opts:       opts,
+			want:       false,
+			wantReason: "[1] scalar values differ",
+		},
+		{
+			name:       "beyond tolerance",
+			a1:         []float64{1, 2},
+			a2:         []float64{1, 2, 3, 4},
+			opts:       opts,
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+		{
+			name:       "nested",
+			a1:         map[string][]int{"cpu": {1, 2}},
+			a2:         map[string][]int{"cpu": {1, 3, 4}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[cpu] [1] scalar values differ",
+		},
+		{
+			name:       "nil slice",
+			a1:         []int(nil),
+			a2:         []int{1},
+			opts:       opts,
+			want:       false,
+			wantReason: "one slice is nil, the other is not",
+		},
+		{
+			name:       "disabled",
+			a1:         []float64{1, 2, 3},
+			a2:         []float64{1, 2, 3, 4},
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+	})
+}
+
+type testAddress struct {
+	City    string
+	Country string
+}
+
+func TestCompareWithOptions_StringAliases(t *testing.T) {
+	opts := Options{StringAliases: map[string]string{"US": "USA", "United States": "USA", "UK": "GBR"}}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "alias and canonical",
+			a1:   testAddress{City: "Austin", Country: "US"},
+			a2:   testAddress{City: "Austin", Country: "USA"},
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "two aliases",
+			a1:   []string{"US", "UK"},
+			a2:   []string{"United States", "GBR"},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "different canonical forms",
+			a1:         testAddress{Country: "US"},
+			a2:         testAddress{Country: "UK"},
+			opts:       opts,
+			want:       false,
+			wantReason: `struct.Country scalar values differ: "US" != "UK"`,
+		},
+		{
+			name:       "different canonical forms with GoSyntax",
+			a1:         testAddress{Country: "United States"},
+			a2:         testAddress{Country: "GBR"},
+			opts:       Options{StringAliases: opts.StringAliases, GoSyntax: true},
+			want:       false,
+			wantReason: `struct.Country scalar values differ: "United States" != "GBR"`,
+		},
+		{
+			name:       "not aliased",
+			a1:         testAddress{City: "Austin", Country: "US"},
+			a2:         testAddress{City: "Boston", Country: "US"},
+			opts:       opts,
+			want:       false,
+			wantReason: `struct.City scalar values differ: "Austin" != "Boston"`,
+		},
+		{
+			name:       "map keys",
+			a1:         map[string]int{"US": 1},
+			a2:         map[string]int{"USA": 1},
+			opts:       opts,
+			want:       false,
+			wantReason: "[US] invalid values are not equal",
+		},
+		{
+			name:       "disabled",
+			a1:         testAddress{Country: "US"},
+			a2:         testAddress{Country: "USA"},
+			want:       false,
+			wantReason: "struct.Country scalar values differ",
+		},
+	})
+}
+
+type testReport struct {
+	Name    string
+	Series  [][]int
+	Labels  map[string]string
+	Metrics map[int]float64
+}
+
+func TestCompareWithOptions_JSONPathPaths(t *testing.T) {
+	opts := Options{JSONPathPaths: true}
+	runOptionsTests(t, []optionsTest{
+		{
+			name:       "nested slices",
+			a1:         testReport{Series: [][]int{{1, 2}, {3, 4}}},
+			a2:         testReport{Series: [][]int{{1, 2}, {3, 5}}},
+			opts:       opts,
+			want:       false,
+			wantReason: "Series.1.1 scalar values differ",
+		},
+		{
+			name:       "map keys",
+			a1:         []testReport{{Metrics: map[int]float64{7: 1}}},
+			a2:         []testReport{{Metrics: map[int]float64{7: 2}}},
+			opts:       opts,
+			want:       false,
+			wantReason: "0.Metrics.7 scalar values differ",
+		},
+		{
+			name:       "escaped key",
+			a1:         testReport{Labels: map[string]string{"app.kubernetes.io/name": "a"}},
+			a2:         testReport{Labels: map[string]string{"app.kubernetes.io/name": "b"}},
+			opts:       opts,
+			want:       false,
+			wantReason: `Labels.app\.kubernetes\.io/name scalar values differ`,
+		},
+		{
+			name:       "pointer",
+			a1:         &testReport{Name: "a"},
+			a2:         &testReport{Name: "b"},
+			opts:       opts,
+			want:       false,
+			wantReason: "Name scalar values differ",
+		},
+		{
+			name:       "top",
+			a1:         1,
+			a2:         2,
+			opts:       opts,
+			want:       false,
+			wantReason: "scalar values differ",
+		},
+		{
+			name: "equal",
+			a1:   testReport{Series: [][]int{{1}}},
+			a2:   testReport{Series: [][]int{{1}}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         testReport{Series: [][]int{{1, 2}, {3, 4}}},
+			a2:         testReport{Series: [][]int{{1, 2}, {3, 5}}},
+			want:       false,
+			wantReason: "struct.Series [1] [1] scalar values differ",
+		},
+	})
+}
+
+type testConn struct {
+	Addr string
+}
+
+type testClient struct {
+	Conn   *testConn
+	Limits *testLimits
+}
+
+func TestCompareWithOptions_RequirePointerIdentity(t *testing.T) {
+	conn := &testConn{Addr: "db:5432"}
+	opts := Options{RequirePointerIdentity: map[reflect.Type]bool{reflect.TypeOf(testConn{}): true}}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "shared instance",
+			a1:   testClient{Conn: conn, Limits: &testLimits{1, 2}},
+			a2:   testClient{Conn: conn, Limits: &testLimits{1, 2}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "equal copy",
+			a1:         testClient{Conn: conn},
+			a2:         testClient{Conn: &testConn{Addr: "db:5432"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Conn expected same deepequal.testConn instance",
+		},
+		{
+			name:       "other types by value",
+			a1:         testClient{Conn: conn, Limits: &testLimits{1, 2}},
+			a2:         testClient{Conn: conn, Limits: &testLimits{1, 3}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Limits struct.Max scalar values differ",
+		},
+		{
+			name:       "nil",
+			a1:         testClient{Conn: conn},
+			a2:         testClient{},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Conn one pointer is nil, the other is not",
+		},
+		{
+			name: "disabled",
+			a1:   testClient{Conn: conn},
+			a2:   testClient{Conn: &testConn{Addr: "db:5432"}},
+			want: true,
+		},
+	})
+}
+
+type testPayload struct {
+	Items  []string
+	Attrs  map[string]string
+	Tags   *[]string
+	Extra  *map[string]int
+	Parent *testPayload
+}
+
+func TestCompareWithOptions_NormalizeNils(t *testing.T) {
+	opts := Options{NormalizeNils: true}
+	var nilTags []string
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "nil and empty",
+			a1:   testPayload{},
+			a2:   testPayload{Items: []string{}, Attrs: map[string]string{}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "nil pointer and pointer to nil slice",
+			a1:   testPayload{Tags: nil},
+			a2:   testPayload{Tags: &nilTags},
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "pointer to nil slice and nil pointer",
+			a1:   testPayload{Tags: &nilTags},
+			a2:   testPayload{Tags: nil},
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "nil pointer and pointer to empty map",
+			a1:   testPayload{Extra: &map[string]int{}},
+			a2:   testPayload{},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "nil pointer and pointer to non-empty slice",
+			a1:         testPayload{},
+			a2:         testPayload{Tags: &[]string{"a"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Tags one pointer is nil, the other is not",
+		},
+		{
+			name:       "nil pointer to struct",
+			a1:         testPayload{},
+			a2:         testPayload{Parent: &testPayload{}},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Parent one pointer is nil, the other is not",
+		},
+		{
+			name: "nested",
+			a1:   []testPayload{{Parent: &testPayload{Tags: &[]string{}}}},
+			a2:   []testPayload{{Parent: &testPayload{Items: []string{}}}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "nil interface",
+			a1:         []interface{}{nil},
+			a2:         []interface{}{[]string{}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[0] both interfaces must be nil",
+		},
+		{
+			name:       "disabled",
+			a1:         testPayload{Tags: nil},
+			a2:         testPayload{Tags: &nilTags},
+			want:       false,
+			wantReason: "struct.Tags one pointer is nil, the other is not",
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package deepequal
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// parallel tells if n parts of the compared values are compared in
+// parallel. Looking for all differences or the path to the first one is
+// always sequential.
+func (c *comparer) parallel(n int) bool {
+	return c.opts.Parallel > 1 && n > 1 && !c.all && !c.trackFirst && c.node == nil
+}
+
+// parallelEqual compares n parts with eq in Parallel goroutines, each with
+// its own comparer and so its own visited set. The parts are taken in
+// order and the goroutines stop after a part differs, skipping the later
+// parts, so the result is the same as comparing them in order.
+func (c *comparer) parallelEqual(n int, eq func(c *comparer, i int) (bool, string)) (bool, string) {
+	workers := c.opts.Parallel
+	if workers > n {
+		workers = n
+	}
+	next := int64(-1)
+	// first is the index of the first differing part found so far
+	first := int64(n)
+	reasons := make([]string, n)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			p := c.probe()
+			for {
+				i := atomic.AddInt64(&next, 1)
+				if i >= atomic.LoadInt64(&first) {
+					return
+				}
+				if equal, reason := eq(p, int(i)); !equal {
+					reasons[i] = reason
+					for f := atomic.LoadInt64(&first); i < f && !atomic.CompareAndSwapInt64(&first, f, i); {
+						f = atomic.LoadInt64(&first)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if first == int64(n) {
+		return true, ""
+	}
+	return false, reasons[first]
+}
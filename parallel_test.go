@@ -0,0 +1,88 @@
+package deepequal
+
+import (
+	"strconv"
+	"testing"
+)
+
+type testWide struct {
+	A, B, C, D, E, F, G, H []testStruct
+}
+
+func newTestWide(n int) *testWide {
+	w := &testWide{}
+	for _, f := range []*[]testStruct{&w.A, &w.B, &w.C, &w.D, &w.E, &w.F, &w.G, &w.H} {
+		*f = make([]testStruct, n)
+		for i := range *f {
+			(*f)[i] = testStruct{Name: strconv.Itoa(i), S: []int{i, i + 1}, M: map[int]string{i: "v"}}
+		}
+	}
+	return w
+}
+
+func TestCompareWithOptions_Parallel(t *testing.T) {
+	differ := func(change func(w *testWide)) *testWide {
+		w := newTestWide(100)
+		change(w)
+		return w
+	}
+	tests := []optionsTest{
+		{
+			name: "equal",
+			a1:   newTestWide(100),
+			a2:   newTestWide(100),
+			want: true,
+		},
+		{
+			name: "first difference wins",
+			a1:   newTestWide(100),
+			a2: differ(func(w *testWide) {
+				w.H[0].Name = "x"
+				w.C[50].S[1] = 0
+				w.C[70].Name = "x"
+			}),
+			want:       false,
+			wantReason: "struct.C [50] struct.S [1] scalar values differ",
+		},
+		{
+			name:       "slice elements",
+			a1:         newTestWide(100).D,
+			a2:         differ(func(w *testWide) { w.D[99].M[99] = "x"; w.D[98].Name = "x" }).D,
+			want:       false,
+			wantReason: "[98] struct.Name scalar values differ",
+		},
+		{
+			name:       "unexported",
+			a1:         testStructS{_name: "a", Name: "S"},
+			a2:         testStructS{_name: "b", Name: "S"},
+			want:       false,
+			wantReason: "struct._name unexported",
+		},
+	}
+	for _, parallel := range []int{0, 2, 8, 100} {
+		for i := range tests {
+			tests[i].opts = Options{Parallel: parallel}
+		}
+		t.Run(strconv.Itoa(parallel), func(t *testing.T) {
+			runOptionsTests(t, tests)
+		})
+	}
+}
+
+func benchmarkParallel(b *testing.B, parallel int) {
+	w1, w2 := newTestWide(5000), newTestWide(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if equal, reason := CompareWithOptions(w1, w2, Options{Parallel: parallel}); !equal {
+			b.Fatal(reason)
+		}
+	}
+}
+
+func BenchmarkCompareWide(b *testing.B) {
+	benchmarkParallel(b, 0)
+}
+
+func BenchmarkCompareWideParallel(b *testing.B) {
+	benchmarkParallel(b, 8)
+}
@@ -0,0 +1,368 @@
+package deepequal
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// PatchOpType is the kind of a patch operation.
+type PatchOpType int
+
+const (
+	// PatchSet sets the value at the path.
+	PatchSet PatchOpType = iota
+	// PatchDelete deletes the slice element or the map key at the path.
+	PatchDelete
+	// PatchInsert inserts the value into the slice at the index of the
+	// path or adds the map key of the path.
+	PatchInsert
+)
+
+func (t PatchOpType) String() string {
+	switch t {
+	case PatchSet:
+		return "set"
+	case PatchDelete:
+		return "delete"
+	case PatchInsert:
+		return "insert"
+	}
+	return fmt.Sprintf("PatchOpType(%d)", int(t))
+}
+
+// PatchOp is an operation of a patch made by ComparePatch.
+type PatchOp struct {
+	// Path locates the changed value, like in Difference. Pointers are
+	// followed implicitly.
+	Path []PathElem
+	Op   PatchOpType
+	// Value is the new value for PatchSet and PatchInsert.
+	Value interface{}
+}
+
+func (op PatchOp) String() string {
+	path := formatPath(op.Path)
+	if op.Op == PatchDelete {
+		return fmt.Sprintf("%s %s", op.Op, path)
+	}
+	return fmt.Sprintf("%s %s %+v", op.Op, path, op.Value)
+}
+
+// patcher walks two values side by side collecting the operations which
+// transform the first one into the second one.
+type patcher struct {
+	c       *comparer
+	visited map[visit]bool
+	path    []PathElem
+	ops     []PatchOp
+	// inspect walks into structs with unexported fields too, for
+	// operations which are only looked at, not applied.
+	inspect bool
+}
+
+func (p *patcher) add(op PatchOpType, value interface{}) {
+	path := make([]PathElem, len(p.path))
+	copy(path, p.path)
+	p.ops = append(p.ops, PatchOp{Path: path, Op: op, Value: value})
+}
+
+func (p *patcher) set(v2 reflect.Value) {
+	p.add(PatchSet, valueInterface(v2))
+}
+
+func (p *patcher) walkElem(elem PathElem, v1, v2 reflect.Value) {
+	p.path = append(p.path, elem)
+	p.walk(v1, v2)
+	p.path = p.path[:len(p.path)-1]
+}
+
+// hasUnexported tells if the struct type t has unexported fields, which
+// can't be patched.
+func hasUnexported(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Name; name[0] < 'A' || name[0] > 'Z' {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *patcher) walk(v1, v2 reflect.Value) {
+	if !v1.IsValid() || !v2.IsValid() || v1.Type() != v2.Type() {
+		p.set(v2)
+		return
+	}
+	switch v1.Kind() {
+	case reflect.Ptr:
+		if v1.IsNil() || v2.IsNil() {
+			if v1.IsNil() != v2.IsNil() {
+				p.set(v2)
+			}
+			return
+		}
+		v := visit{v1.Pointer(), v2.Pointer(), v1.Type()}
+		if v1.Pointer() == v2.Pointer() || p.visited[v] {
+			return
+		}
+		p.visited[v] = true
+		p.walk(v1.Elem(), v2.Elem())
+	case reflect.Interface:
+		if v1.IsNil() || v2.IsNil() || v1.Elem().Type() != v2.Elem().Type() {
+			if equal, _ := p.c.probe().deepValueEqual(v1, v2, 0); !equal {
+				p.set(v2)
+			}
+			return
+		}
+		p.walk(v1.Elem(), v2.Elem())
+	case reflect.Struct:
+		if !p.inspect && hasUnexported(v1.Type()) {
+			if equal, _ := p.c.probe().deepValueEqual(v1, v2, 0); !equal {
+				p.set(v2)
+			}
+			return
+		}
+		for i := 0; i < v1.NumField(); i++ {
+			p.walkElem(fieldElem(v1.Type().Field(i).Name), v1.Field(i), v2.Field(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v1.Len(); i++ {
+			p.walkElem(indexElem(i), v1.Index(i), v2.Index(i))
+		}
+	case reflect.Slice:
+		if v1.IsNil() != v2.IsNil() {
+			p.set(v2)
+			return
+		}
+		n1, n2 := v1.Len(), v2.Len()
+		n := n1
+		if n2 < n {
+			n = n2
+		}
+		for i := 0; i < n; i++ {
+			p.walkElem(indexElem(i), v1.Index(i), v2.Index(i))
+		}
+		// delete from the end, so the indices stay valid
+		for i := n1 - 1; i >= n2; i-- {
+			p.path = append(p.path, indexElem(i))
+			p.add(PatchDelete, nil)
+			p.path = p.path[:len(p.path)-1]
+		}
+		for i := n1; i < n2; i++ {
+			p.path = append(p.path, indexElem(i))
+			p.add(PatchInsert, valueInterface(v2.Index(i)))
+			p.path = p.path[:len(p.path)-1]
+		}
+	case reflect.Map:
+		if v1.IsNil() != v2.IsNil() {
+			p.set(v2)
+			return
+		}
+		keys := v1.MapKeys()
+		for _, k := range v2.MapKeys() {
+			if !v1.MapIndex(k).IsValid() {
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+		for _, k := range keys {
+			e1, e2 := v1.MapIndex(k), v2.MapIndex(k)
+			p.path = append(p.path, keyElem(k))
+			switch {
+			case !e2.IsValid():
+				p.add(PatchDelete, nil)
+			case !e1.IsValid():
+				p.add(PatchInsert, valueInterface(e2))
+			default:
+				p.walk(e1, e2)
+			}
+			p.path = p.path[:len(p.path)-1]
+		}
+	default:
+		if equal, _ := p.c.probe().deepValueEqual(v1, v2, 0); !equal {
+			p.set(v2)
+		}
+	}
+}
+
+// ComparePatch returns the operations which transform a1 into a2 with
+// ApplyPatch, empty for equal values. Values are compared like with
+// Compare, structs with unexported fields and values of different types
+// are replaced as a whole.
+func ComparePatch(a1, a2 interface{}) []PatchOp {
+	return comparePatch(a1, a2, false)
+}
+
+func comparePatch(a1, a2 interface{}, inspect bool) []PatchOp {
+	p := patcher{c: newComparer(&Options{}), visited: make(map[visit]bool), inspect: inspect}
+	p.walk(reflect.ValueOf(a1), reflect.ValueOf(a2))
+	return p.ops
+}
+
+// ApplyPatch applies the operations made by ComparePatch to the value
+// target points to. The value is changed in place, including the slices
+// and maps it references.
+func ApplyPatch(target interface{}, ops []PatchOp) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, not %T", target)
+	}
+	for _, op := range ops {
+		if err := applyOp(v.Elem(), op.Path, op); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// patchValue converts the value of an operation to a value of type t.
+func patchValue(value interface{}, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(t), nil
+	}
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("%v is not assignable to %v", v.Type(), t)
+	}
+	return v, nil
+}
+
+func applyOp(v reflect.Value, path []PathElem, op PatchOp) error {
+	if len(path) == 0 {
+		if op.Op != PatchSet {
+			return fmt.Errorf("%s needs a slice index or a map key", op.Op)
+		}
+		value, err := patchValue(op.Value, v.Type())
+		if err != nil {
+			return err
+		}
+		v.Set(value)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return fmt.Errorf("nil pointer at %s", path[0])
+		}
+		return applyOp(v.Elem(), path, op)
+	case reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("nil interface at %s", path[0])
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := applyOp(elem, path, op); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	e := path[0]
+	switch {
+	case e.Kind == PathField && v.Kind() == reflect.Struct:
+		f := v.FieldByName(e.Name)
+		if !f.IsValid() || !f.CanSet() {
+			return fmt.Errorf("no settable field %s", e.Name)
+		}
+		return applyOp(f, path[1:], op)
+	case e.Kind == PathIndex && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		return applyIndex(v, e.Index, path[1:], op)
+	case e.Kind == PathKey && v.Kind() == reflect.Map:
+		return applyKey(v, e.Key, path[1:], op)
+	}
+	return fmt.Errorf("%s doesn't apply to %v", e, v.Type())
+}
+
+func applyIndex(v reflect.Value, i int, path []PathElem, op PatchOp) error {
+	if len(path) == 0 && op.Op != PatchSet {
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("%s needs a slice", op.Op)
+		}
+		if op.Op == PatchDelete {
+			if i < 0 || i >= v.Len() {
+				return fmt.Errorf("index %d out of range", i)
+			}
+			v.Set(reflect.AppendSlice(v.Slice(0, i), v.Slice(i+1, v.Len())))
+			return nil
+		}
+		if i < 0 || i > v.Len() {
+			return fmt.Errorf("index %d out of range", i)
+		}
+		value, err := patchValue(op.Value, v.Type().Elem())
+		if err != nil {
+			return err
+		}
+		tail := reflect.AppendSlice(reflect.MakeSlice(v.Type(), 0, v.Len()-i), v.Slice(i, v.Len()))
+		v.Set(reflect.AppendSlice(reflect.Append(v.Slice(0, i), value), tail))
+		return nil
+	}
+	if i < 0 || i >= v.Len() {
+		return fmt.Errorf("index %d out of range", i)
+	}
+	return applyOp(v.Index(i), path, op)
+}
+
+func applyKey(v reflect.Value, key interface{}, path []PathElem, op PatchOp) error {
+	k, err := patchValue(key, v.Type().Key())
+	if err != nil {
+		return err
+	}
+	if len(path) == 0 {
+		if op.Op == PatchDelete {
+			v.SetMapIndex(k, reflect.Value{})
+			return nil
+		}
+		value, err := patchValue(op.Value, v.Type().Elem())
+		if err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(k, value)
+		return nil
+	}
+	e := v.MapIndex(k)
+	if !e.IsValid() {
+		return fmt.Errorf("no key %+v", key)
+	}
+	// map values aren't addressable, change a copy
+	elem := reflect.New(e.Type()).Elem()
+	elem.Set(e)
+	if err := applyOp(elem, path, op); err != nil {
+		return err
+	}
+	v.SetMapIndex(k, elem)
+	return nil
+}
+
+// CompareAdditive tells if new only adds to old: map keys and slice
+// elements at the end, or contents of slices and maps which were nil.
+// Otherwise breaking lists the other changes, like '[b] removed' or
+// 'struct.Name changed', in the order of ComparePatch. Unlike ComparePatch
+// it compares structs with unexported fields field by field, instead of
+// as a whole.
+func CompareAdditive(old, new interface{}) (compatible bool, breaking []string) {
+	v := reflect.ValueOf(old)
+	for _, op := range comparePatch(old, new, true) {
+		var reason string
+		switch op.Op {
+		case PatchInsert:
+			continue
+		case PatchDelete:
+			reason = "removed"
+		default:
+			if prev, ok := follow(v, op.Path); ok && isNilContainer(prev) {
+				continue
+			}
+			reason = "changed"
+		}
+		breaking = append(breaking, Difference{Path: op.Path, Reason: reason}.String())
+	}
+	return len(breaking) == 0, breaking
+}
+
+// isNilContainer tells if v is a nil slice or map.
+func isNilContainer(v reflect.Value) bool {
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil()
+}
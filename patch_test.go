@@ -0,0 +1,265 @@
+package deepequal
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testInventory struct {
+	Name   string
+	Items  []testItem
+	Stock  map[string]int
+	Owner  *testAuthor
+	Extra  interface{}
+	Matrix [2][]int
+}
+
+type testItem struct {
+	ID   int
+	Tags []string
+}
+
+type testRegistry struct {
+	Entries map[string]int
+	version int
+}
+
+func TestComparePatch(t *testing.T) {
+	base := func() testInventory {
+		return testInventory{
+			Name:   "inv",
+			Items:  []testItem{{ID: 1, Tags: []string{"a"}}, {ID: 2}, {ID: 3}},
+			Stock:  map[string]int{"a": 1, "b": 2},
+			Owner:  &testAuthor{Name: "x"},
+			Extra:  map[string]interface{}{"k": []interface{}{1.0, "s"}},
+			Matrix: [2][]int{{1}, {2}},
+		}
+	}
+	tests := []struct {
+		name    string
+		change  func(v *testInventory)
+		wantOps []string
+	}{
+		{
+			name:   "equal",
+			change: func(v *testInventory) {},
+		},
+		{
+			name:    "field",
+			change:  func(v *testInventory) { v.Name = "new" },
+			wantOps: []string{"set struct.Name new"},
+		},
+		{
+			name: "slice grows",
+			change: func(v *testInventory) {
+				v.Items[0].Tags = append(v.Items[0].Tags, "b")
+				v.Items = append(v.Items, testItem{ID: 4})
+			},
+			wantOps: []string{"insert struct.Items [0] struct.Tags [1] b", "insert struct.Items [3] {ID:4 Tags:[]}"},
+		},
+		{
+			name:    "slice shrinks",
+			change:  func(v *testInventory) { v.Items = v.Items[:1] },
+			wantOps: []string{"delete struct.Items [2]", "delete struct.Items [1]"},
+		},
+		{
+			name: "map",
+			change: func(v *testInventory) {
+				v.Stock = map[string]int{"b": 3, "c": 1}
+			},
+			wantOps: []string{"delete struct.Stock [a]", "set struct.Stock [b] 3", "insert struct.Stock [c] 1"},
+		},
+		{
+			name:    "pointer",
+			change:  func(v *testInventory) { v.Owner = &testAuthor{Name: "y"} },
+			wantOps: []string{"set struct.Owner struct.Name y"},
+		},
+		{
+			name:    "nil pointer",
+			change:  func(v *testInventory) { v.Owner = nil },
+			wantOps: []string{"set struct.Owner <nil>"},
+		},
+		{
+			name: "interface",
+			change: func(v *testInventory) {
+				v.Extra = map[string]interface{}{"k": []interface{}{1.0, 2.0}}
+			},
+			wantOps: []string{"set struct.Extra [k] [1] 2"},
+		},
+		{
+			name:    "interface type",
+			change:  func(v *testInventory) { v.Extra = 1 },
+			wantOps: []string{"set struct.Extra 1"},
+		},
+		{
+			name:    "array",
+			change:  func(v *testInventory) { v.Matrix[1] = nil },
+			wantOps: []string{"set struct.Matrix [1] []"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a1, a2 := base(), base()
+			tt.change(&a2)
+			ops := ComparePatch(a1, a2)
+			var got []string
+			for _, op := range ops {
+				got = append(got, op.String())
+			}
+			if !reflect.DeepEqual(got, tt.wantOps) {
+				t.Errorf("ComparePatch() = %q, want %q", got, tt.wantOps)
+			}
+			if err := ApplyPatch(&a1, ops); err != nil {
+				t.Fatalf("ApplyPatch() error = %v", err)
+			}
+			if equal, reason := Compare(a1, a2); !equal {
+				t.Errorf("patched value differs: %s", reason)
+			}
+		})
+	}
+}
+
+func TestComparePatch_Unexported(t *testing.T) {
+	a1 := []testStructS{{_name: "a", Name: "x"}}
+	a2 := []testStructS{{_name: "b", Name: "x"}}
+	ops := ComparePatch(a1, a2)
+	if len(ops) != 1 || ops[0].Op != PatchSet || len(ops[0].Path) != 1 {
+		t.Fatalf("ComparePatch() = %v, want the element set", ops)
+	}
+	if err := ApplyPatch(&a1, ops); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	if !reflect.DeepEqual(a1, a2) {
+		t.Errorf("patched value = %+v, want %+v", a1, a2)
+	}
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	v := testInventory{}
+	tests := []struct {
+		name   string
+		target interface{}
+		ops    []PatchOp
+	}{
+		{name: "not a pointer", target: v},
+		{name: "nil pointer", target: (*testInventory)(nil)},
+		{
+			name:   "no field",
+			target: &v,
+			ops:    []PatchOp{{Path: []PathElem{fieldElem("Missing")}, Value: 1}},
+		},
+		{
+			name:   "wrong type",
+			target: &v,
+			ops:    []PatchOp{{Path: []PathElem{fieldElem("Name")}, Value: 1}},
+		},
+		{
+			name:   "index out of range",
+			target: &v,
+			ops:    []PatchOp{{Path: []PathElem{fieldElem("Items"), indexElem(1)}, Op: PatchDelete}},
+		},
+		{
+			name:   "insert at root",
+			target: &v,
+			ops:    []PatchOp{{Op: PatchInsert, Value: testInventory{}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ApplyPatch(tt.target, tt.ops); err == nil {
+				t.Error("ApplyPatch() error = nil")
+			}
+		})
+	}
+}
+
+func TestCompareAdditive(t *testing.T) {
+	old := testInventory{
+		Name:  "a",
+		Items: []testItem{{ID: 1, Tags: []string{"x"}}},
+		Stock: map[string]int{"x": 1},
+	}
+	tests := []struct {
+		name         string
+		old          interface{}
+		new          interface{}
+		want         bool
+		wantBreaking []string
+	}{
+		{
+			name: "equal",
+			old:  old,
+			new:  old,
+			want: true,
+		},
+		{
+			name: "additions",
+			old:  old,
+			new: testInventory{
+				Name:  "a",
+				Items: []testItem{{ID: 1, Tags: []string{"x"}}, {ID: 2}},
+				Stock: map[string]int{"x": 1, "y": 2},
+			},
+			want: true,
+		},
+		{
+			name: "nil map filled",
+			old:  map[string][]int{"a": nil},
+			new:  map[string][]int{"a": {1, 2}, "b": {3}},
+			want: true,
+		},
+		{
+			name: "breaking",
+			old:  old,
+			new: testInventory{
+				Name:  "b",
+				Items: []testItem{{ID: 5, Tags: []string{"x"}}},
+				Stock: map[string]int{"y": 1},
+			},
+			want: false,
+			wantBreaking: []string{
+				"struct.Name changed",
+				"struct.Items [0] struct.ID changed",
+				"struct.Stock [x] removed",
+			},
+		},
+		{
+			name:         "shortened slice",
+			old:          []int{1, 2, 3},
+			new:          []int{1},
+			want:         false,
+			wantBreaking: []string{"[2] removed", "[1] removed"},
+		},
+		{
+			name: "struct with unexported field",
+			old:  testRegistry{Entries: map[string]int{"a": 1}, version: 1},
+			new:  testRegistry{Entries: map[string]int{"a": 1, "b": 2}, version: 1},
+			want: true,
+		},
+		{
+			name:         "unexported field changed",
+			old:          testRegistry{Entries: map[string]int{"a": 1}, version: 1},
+			new:          testRegistry{Entries: map[string]int{"b": 2}, version: 2},
+			want:         false,
+			wantBreaking: []string{"struct.Entries [a] removed", "struct.version changed"},
+		},
+		{
+			name:         "map emptied to nil",
+			old:          map[string]int{},
+			new:          map[string]int(nil),
+			want:         false,
+			wantBreaking: []string{"changed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotBreaking := CompareAdditive(tt.old, tt.new)
+			if got != tt.want {
+				t.Errorf("CompareAdditive() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotBreaking, tt.wantBreaking) {
+				t.Errorf("CompareAdditive() got1 = %q, want %q", gotBreaking, tt.wantBreaking)
+			}
+		})
+	}
+}
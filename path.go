@@ -0,0 +1,186 @@
+package deepequal
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// PathKind is the kind of a step into a compared value.
+type PathKind int
+
+const (
+	// PathField is a struct field.
+	PathField PathKind = iota
+	// PathIndex is a slice or array element.
+	PathIndex
+	// PathKey is a map value.
+	PathKey
+	// PathMethod is the result of a method called without arguments.
+	PathMethod
+)
+
+// PathElem is a step into a compared value. Differences are located by
+// the list of steps from the compared values.
+type PathElem struct {
+	Kind PathKind
+	// Name is the struct field name for PathField or the method name for
+	// PathMethod.
+	Name string
+	// Index is the element index for PathIndex.
+	Index int
+	// Key is the map key for PathKey. For keys which can't be converted
+	// with Interface it holds their formatted form.
+	Key interface{}
+}
+
+// String formats the step as it's shown in reasons: 'struct.NAME',
+// '[INDEX]', '[KEY]' or 'NAME()'.
+func (e PathElem) String() string {
+	switch e.Kind {
+	case PathField:
+		return "struct." + e.Name
+	case PathIndex:
+		return fmt.Sprintf("[%d]", e.Index)
+	case PathMethod:
+		return e.Name + "()"
+	}
+	return fmt.Sprintf("[%+v]", e.Key)
+}
+
+func fieldElem(name string) PathElem {
+	return PathElem{Kind: PathField, Name: name}
+}
+
+func indexElem(i int) PathElem {
+	return PathElem{Kind: PathIndex, Index: i}
+}
+
+func methodElem(name string) PathElem {
+	return PathElem{Kind: PathMethod, Name: name}
+}
+
+func keyElem(k reflect.Value) PathElem {
+	return PathElem{Kind: PathKey, Key: valueInterface(k)}
+}
+
+// valueInterface returns the value held by v as interface{}, or its
+// formatted form if it can't be converted with Interface.
+func valueInterface(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if !v.CanInterface() {
+		return fmt.Sprintf("%+v", v)
+	}
+	return v.Interface()
+}
+
+// descend compares the values v1 and v2 found at elem with cmp, tracking
+// the path to them. The reason of a difference is prefixed by elem.
+//
+// When all differences are wanted, descend records them and returns
+// false with an empty reason, so the caller carries on with the next
+// element.
+func (c *comparer) descend(elem PathElem, v1, v2 reflect.Value, depth int, cmp func(v1, v2 reflect.Value, depth int) (bool, string)) (bool, string) {
+	c.path = append(c.path, elem)
+	parent := c.node
+	if parent != nil {
+		c.node = &DiffNode{Name: elem.String()}
+		parent.Children = append(parent.Children, c.node)
+	}
+
+	equal, reason := cmp(v1, v2, depth+1)
+	if !equal && reason != "" {
+		// the innermost call gets the reason first, before it's prefixed
+		if c.trackFirst && c.first == nil {
+			d := c.newDifference(v1, v2, reason)
+			c.first = &d
+		}
+		if c.all {
+			c.difference(v1, v2, reason)
+			reason = ""
+		}
+	}
+
+	if parent != nil {
+		c.node.Equal = equal
+		c.node = parent
+	}
+	c.path = c.path[:len(c.path)-1]
+
+	if equal || reason == "" {
+		return equal, ""
+	}
+	return false, c.elemString(elem) + " " + reason
+}
+
+// elemString formats elem for a reason, with the map key clipped to
+// MaxReasonLen.
+func (c *comparer) elemString(elem PathElem) string {
+	if elem.Kind == PathKey && c.opts.MaxReasonLen > 0 {
+		return "[" + c.clip(fmt.Sprintf("%+v", elem.Key)) + "]"
+	}
+	return elem.String()
+}
+
+// elemDiff reports a difference of the values v1 and v2 found at elem
+// without comparing them, as descend does.
+func (c *comparer) elemDiff(elem PathElem, v1, v2 reflect.Value, reason string) (bool, string) {
+	return c.descend(elem, v1, v2, 0, func(reflect.Value, reflect.Value, int) (bool, string) {
+		return false, reason
+	})
+}
+
+// difference records a difference of v1 and v2 at the current path.
+func (c *comparer) difference(v1, v2 reflect.Value, reason string) {
+	if c.report != nil {
+		c.report(c.newDifference(v1, v2, reason))
+	}
+	if c.node != nil {
+		c.node.Reason = reason
+		if len(c.node.Children) == 0 {
+			c.node.A, c.node.B = valueInterface(v1), valueInterface(v2)
+		}
+	}
+}
+
+// follow returns the value found by following path from v, looking
+// through pointers and interfaces. ok is false if the path doesn't exist
+// in v, like for a missing map key or a nil pointer.
+func follow(v reflect.Value, path []PathElem) (reflect.Value, bool) {
+	for _, e := range path {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		switch {
+		case e.Kind == PathField && v.Kind() == reflect.Struct:
+			v = v.FieldByName(e.Name)
+		case e.Kind == PathIndex && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+			if e.Index < 0 || e.Index >= v.Len() {
+				return reflect.Value{}, false
+			}
+			v = v.Index(e.Index)
+		case e.Kind == PathKey && v.Kind() == reflect.Map:
+			k := reflect.ValueOf(e.Key)
+			if !k.IsValid() || !k.Type().AssignableTo(v.Type().Key()) {
+				return reflect.Value{}, false
+			}
+			v = v.MapIndex(k)
+		case e.Kind == PathMethod:
+			m, recv, ok := findMethod(v, e.Name)
+			if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() == 0 {
+				return reflect.Value{}, false
+			}
+			v = callMethod(recv, e.Name)
+		default:
+			return reflect.Value{}, false
+		}
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,92 @@
+package deepequal
+
+import (
+	"reflect"
+)
+
+// sizer estimates the memory used by values, counting every pointer and
+// map target and every slice once.
+type sizer struct {
+	seen   map[uintptr]bool
+	slices map[sliceHeader]bool
+}
+
+// sliceHeader identifies the elements of a slice.
+type sliceHeader struct {
+	data uintptr
+	len  int
+}
+
+// size returns the size of v itself and of everything it references.
+func (s *sizer) size(v reflect.Value) int {
+	if !v.IsValid() {
+		return 0
+	}
+	return int(v.Type().Size()) + s.referenced(v)
+}
+
+// referenced returns the size of what v references.
+func (s *sizer) referenced(v reflect.Value) int {
+	n := 0
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || s.seen[v.Pointer()] {
+			return 0
+		}
+		s.seen[v.Pointer()] = true
+		n = s.size(v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			n = s.size(v.Elem())
+		}
+	case reflect.String:
+		n = v.Len()
+	case reflect.Slice:
+		// a slice may reach itself through an interface
+		h := sliceHeader{v.Pointer(), v.Len()}
+		if h.len == 0 || s.slices[h] {
+			return 0
+		}
+		s.slices[h] = true
+		for i := 0; i < v.Len(); i++ {
+			n += s.size(v.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			n += s.referenced(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			n += s.referenced(v.Field(i))
+		}
+	case reflect.Map:
+		if v.IsNil() || s.seen[v.Pointer()] {
+			return 0
+		}
+		s.seen[v.Pointer()] = true
+		iter := v.MapRange()
+		for iter.Next() {
+			n += s.size(iter.Key()) + s.size(iter.Value())
+		}
+	}
+	return n
+}
+
+// Size estimates the memory used by a, in bytes: the size of the value
+// itself and of the strings, slice elements, map entries and pointer
+// targets reachable from it, each counted once, so cycles terminate.
+// Slices are counted once per start and length, the shared part of
+// overlapping ones is counted for each of them. Allocator overhead and
+// slice capacity beyond the length aren't counted.
+func Size(a interface{}) int {
+	s := sizer{seen: make(map[uintptr]bool), slices: make(map[sliceHeader]bool)}
+	return s.size(reflect.ValueOf(a))
+}
+
+// CompareSized compares a1 and a2 like Compare and also returns their
+// estimated sizes (see Size), to tell whether a slow comparison is due to
+// large inputs.
+func CompareSized(a1, a2 interface{}) (equal bool, reason string, size1, size2 int) {
+	equal, reason = Compare(a1, a2)
+	return equal, reason, Size(a1), Size(a2)
+}
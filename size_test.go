@@ -0,0 +1,64 @@
+package deepequal
+
+import (
+	"testing"
+	"unsafe"
+)
+
+type testSizeNode struct {
+	Name string
+	Next *testSizeNode
+}
+
+func TestSize(t *testing.T) {
+	loop := &testSizeNode{Name: "ab"}
+	loop.Next = loop
+	self := make([]interface{}, 1)
+	self[0] = self
+	self32 := []int32{1, 2}
+	ptr := int(unsafe.Sizeof(loop))
+	str := int(unsafe.Sizeof(""))
+	slice := int(unsafe.Sizeof([]int32(nil)))
+	iface := int(unsafe.Sizeof(interface{}(nil)))
+	tests := []struct {
+		name string
+		a    interface{}
+		want int
+	}{
+		{name: "nil", a: nil, want: 0},
+		{name: "int64", a: int64(1), want: 8},
+		{name: "string", a: "abc", want: str + 3},
+		{name: "slice", a: []int32{1, 2, 3}, want: slice + 3*4},
+		{name: "array of strings", a: [2]string{"a", "bc"}, want: 2*str + 3},
+		{name: "map", a: map[int64]int64{1: 2}, want: ptr + 16},
+		{name: "cycle", a: loop, want: ptr + str + ptr + 2},
+		{name: "interfaces", a: []interface{}{int64(1), "a"}, want: slice + 2*iface + 8 + str + 1},
+		{name: "slice cycle", a: self, want: slice + iface + slice},
+		{name: "shared slice", a: [][]int32{self32, self32}, want: slice + 2*slice + 2*4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Size(tt.a); got != tt.want {
+				t.Errorf("Size() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareSized(t *testing.T) {
+	small := testStruct{Name: "a", S: []int{1}}
+	large := testStruct{Name: "a", S: make([]int, 1000), M: map[int]string{1: "x"}}
+
+	equal, reason, size1, size2 := CompareSized(small, large)
+	if equal || reason != "struct.S slices have different lengths" {
+		t.Errorf("CompareSized() = %v, %q", equal, reason)
+	}
+	if size1 >= size2 {
+		t.Errorf("CompareSized() sizes = %d, %d, want the first one smaller", size1, size2)
+	}
+
+	equal, _, size1, size2 = CompareSized(large, large)
+	if !equal || size1 != size2 {
+		t.Errorf("CompareSized() = %v, sizes %d, %d, want equal sizes", equal, size1, size2)
+	}
+}
@@ -0,0 +1,388 @@
+package deepequal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+)
+
+// sliceValues checks that a1 and a2 are slices or arrays with the same
+// element type and returns their values.
+func sliceValues(a1, a2 interface{}) (v1, v2 reflect.Value, reason string) {
+	v1 = reflect.ValueOf(a1)
+	v2 = reflect.ValueOf(a2)
+	isSlice := func(v reflect.Value) bool {
+		return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	}
+	if !isSlice(v1) || !isSlice(v2) {
+		return v1, v2, "values are not slices or arrays"
+	}
+	if v1.Type().Elem() != v2.Type().Elem() {
+		return v1, v2, "slices have different element types"
+	}
+	return v1, v2, ""
+}
+
+// CompareSlicePrefix tests that the slice (or array) actual starts with the
+// elements of expected. Elements are compared as with Compare.
+func CompareSlicePrefix(expected, actual interface{}) (bool, string) {
+	v1, v2, reason := sliceValues(expected, actual)
+	if reason != "" {
+		return false, reason
+	}
+	if v2.Len() < v1.Len() {
+		return false, "actual shorter than expected"
+	}
+	c := newComparer(&Options{})
+	for i := 0; i < v1.Len(); i++ {
+		if equal, reason := c.deepValueEqual(v1.Index(i), v2.Index(i), 1); !equal {
+			return false, fmt.Sprintf("[%d] %s", i, reason)
+		}
+	}
+	return true, ""
+}
+
+// CompareSliceFunc tests slices (or arrays) a and b for equality, comparing
+// corresponding elements with eq instead of deep equality.
+func CompareSliceFunc(a, b interface{}, eq func(x, y interface{}) bool) (bool, string) {
+	v1, v2, reason := sliceValues(a, b)
+	if reason != "" {
+		return false, reason
+	}
+	if v1.Len() != v2.Len() {
+		return false, "slices have different lengths"
+	}
+	for i := 0; i < v1.Len(); i++ {
+		if !eq(v1.Index(i).Interface(), v2.Index(i).Interface()) {
+			return false, fmt.Sprintf("[%d] elements differ", i)
+		}
+	}
+	return true, ""
+}
+
+// unorderedEqual compares slices of the same length ignoring the order of
+// elements: each element of v1 is matched with the first equal element of
+// v2 not matched yet.
+func (c *comparer) unorderedEqual(v1, v2 reflect.Value, depth int) (bool, string) {
+	matched := make([]bool, v2.Len())
+	result := true
+	for i := 0; i < v1.Len(); i++ {
+		found := false
+		for j := 0; j < v2.Len(); j++ {
+			if matched[j] {
+				continue
+			}
+			// failed attempts must not be remembered as visited
+			if equal, _ := c.probe().deepValueEqual(v1.Index(i), v2.Index(j), depth+1); equal {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		reason := "unmatched element " + c.clip(fmt.Sprintf("%+v", v1.Index(i)))
+		if equal, reason := c.elemDiff(indexElem(i), v1.Index(i), reflect.Value{}, reason); !c.all {
+			return equal, reason
+		}
+		result = false
+	}
+	return result, ""
+}
+
+// sliceMask compares the slices (or arrays) v1 and v2 of the same type
+// element by element, like Compare, and marks the indices of the differing
+// elements, up to the longer length. The indices past the shorter length
+// differ.
+func sliceMask(v1, v2 reflect.Value) []bool {
+	n1, n2 := v1.Len(), v2.Len()
+	n := n1
+	if n2 > n {
+		n = n2
+	}
+	c := newComparer(&Options{})
+	mask := make([]bool, n)
+	for i := range mask {
+		if i < n1 && i < n2 {
+			if equal, _ := c.probe().deepValueEqual(v1.Index(i), v2.Index(i), 1); equal {
+				continue
+			}
+		}
+		mask[i] = true
+	}
+	return mask
+}
+
+// CompareSliceMask compares slices (or arrays) a and b element by element,
+// like Compare, and returns a mask with the length of the longer one,
+// which is true at the indices of the differing elements, and of the
+// elements missing in the shorter one. Only the elements are compared, so
+// a nil slice equals an empty one. For other values, or slices of
+// different types, the mask is nil and they are compared like Compare.
+func CompareSliceMask(a, b interface{}) (bool, []bool) {
+	v1, v2 := reflect.ValueOf(a), reflect.ValueOf(b)
+	if a == nil || b == nil || v1.Type() != v2.Type() || v1.Kind() != reflect.Slice && v1.Kind() != reflect.Array {
+		equal, _ := Compare(a, b)
+		return equal, nil
+	}
+	mask := sliceMask(v1, v2)
+	for _, differ := range mask {
+		if differ {
+			return false, mask
+		}
+	}
+	return true, mask
+}
+
+// truncateSlice returns the first n elements of the slice v.
+func truncateSlice(v reflect.Value, n int) reflect.Value {
+	if v.Len() <= n {
+		return v
+	}
+	return v.Slice(0, n)
+}
+
+// backingOverlap finds the elements shared by the backing arrays of the
+// slices v1 and v2 of the same type, up to their capacities: v1[lo1:hi1]
+// is v2[lo2:lo2+hi1-lo1]. ok is false if they share no memory.
+func backingOverlap(v1, v2 reflect.Value) (lo1, hi1, lo2 int, ok bool) {
+	size := v1.Type().Elem().Size()
+	if size == 0 || v1.Cap() == 0 || v2.Cap() == 0 {
+		return 0, 0, 0, false
+	}
+	start1, start2 := v1.Pointer(), v2.Pointer()
+	end1, end2 := start1+uintptr(v1.Cap())*size, start2+uintptr(v2.Cap())*size
+	if start1 >= end2 || start2 >= end1 {
+		return 0, 0, 0, false
+	}
+	start, end := start1, end1
+	if start2 > start {
+		start = start2
+	}
+	if end2 < end {
+		end = end2
+	}
+	return int((start - start1) / size), int((end - start1) / size), int((start - start2) / size), true
+}
+
+// SliceOverlap tells if the slices a and b share memory of their backing
+// arrays, up to their capacities, so appending to one may change the
+// other. info describes the shared elements, like 'a[2:5] is b[0:3]',
+// with indices past the length of a slice (but within its capacity)
+// marked as such.
+func SliceOverlap(a, b interface{}) (overlap bool, info string) {
+	v1, v2 := reflect.ValueOf(a), reflect.ValueOf(b)
+	if a == nil || b == nil || v1.Kind() != reflect.Slice || v1.Type() != v2.Type() {
+		return false, "values are not slices of the same type"
+	}
+	lo1, hi1, lo2, ok := backingOverlap(v1, v2)
+	if !ok {
+		return false, ""
+	}
+	hi2 := lo2 + hi1 - lo1
+	info = fmt.Sprintf("a[%d:%d] is b[%d:%d]", lo1, hi1, lo2, hi2)
+	if hi1 > v1.Len() || hi2 > v2.Len() {
+		info += " (beyond the length)"
+	}
+	return true, info
+}
+
+// trimZeroEnds returns the slice v without the zero elements at its start
+// and end.
+func trimZeroEnds(v reflect.Value) reflect.Value {
+	lo, hi := 0, v.Len()
+	for lo < hi && v.Index(lo).IsZero() {
+		lo++
+	}
+	for hi > lo && v.Index(hi-1).IsZero() {
+		hi--
+	}
+	return v.Slice(lo, hi)
+}
+
+// sliceDivergence describes how slices of different lengths differ: the
+// index where they diverge and whether the longest common subsequence of
+// elements suggests elements were inserted or deleted.
+func (c *comparer) sliceDivergence(v1, v2 reflect.Value, depth int) string {
+	n1, n2 := v1.Len(), v2.Len()
+	equal := func(i, j int) bool {
+		eq, _ := c.probe().deepValueEqual(v1.Index(i), v2.Index(j), depth+1)
+		return eq
+	}
+	prefix := 0
+	for prefix < n1 && prefix < n2 && equal(prefix, prefix) {
+		prefix++
+	}
+
+	// the common suffix is part of the LCS too
+	suffix := 0
+	for suffix < n1-prefix && suffix < n2-prefix && equal(n1-1-suffix, n2-1-suffix) {
+		suffix++
+	}
+
+	// LCS length of the rest, with two rows of the table: next[j] is the
+	// LCS length of v1[i+1:] and v2[j:], row[j] of v1[i:] and v2[j:]
+	end1, end2 := n1-suffix, n2-suffix
+	row, next := make([]int, end2+1), make([]int, end2+1)
+	for i := end1 - 1; i >= prefix; i-- {
+		for j := end2 - 1; j >= prefix; j-- {
+			switch {
+			case equal(i, j):
+				row[j] = next[j+1] + 1
+			case next[j] > row[j+1]:
+				row[j] = next[j]
+			default:
+				row[j] = row[j+1]
+			}
+		}
+		row, next = next, row
+	}
+	common := prefix + next[prefix] + suffix
+
+	edit := "changes"
+	if n2 > n1 && common == n1 {
+		edit = "insertion"
+	} else if n1 > n2 && common == n2 {
+		edit = "deletion"
+	}
+	return fmt.Sprintf("diverge at [%d]; lengths %d vs %d suggest %s", prefix, n1, n2, edit)
+}
+
+var runesType = reflect.TypeOf([]rune(nil))
+
+func runes(v reflect.Value) []rune {
+	r := make([]rune, v.Len())
+	for i := range r {
+		r[i] = rune(v.Index(i).Int())
+	}
+	return r
+}
+
+// compareRunes compares []rune values as strings, reporting the index of
+// the first differing rune (or the length of the shorter one).
+func compareRunes(v1, v2 reflect.Value) (bool, string) {
+	r1, r2 := runes(v1), runes(v2)
+	i := 0
+	for i < len(r1) && i < len(r2) && r1[i] == r2[i] {
+		i++
+	}
+	if i == len(r1) && i == len(r2) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("rune slices differ at [%d]: %q != %q", i, string(r1), string(r2))
+}
+
+// compareBase64 compares byte slices at once, reporting them in base64
+// with the offset of the first differing byte (or the length of the
+// shorter one).
+func compareBase64(v1, v2 reflect.Value) (bool, string) {
+	b1, b2 := v1.Bytes(), v2.Bytes()
+	if bytes.Equal(b1, b2) {
+		return true, ""
+	}
+	i := 0
+	for i < len(b1) && i < len(b2) && b1[i] == b2[i] {
+		i++
+	}
+	return false, fmt.Sprintf("bytes differ at offset %d: %s != %s", i,
+		base64.StdEncoding.EncodeToString(b1), base64.StdEncoding.EncodeToString(b2))
+}
+
+// hashable tells if the value v can be used as a map key: its type is
+// comparable and so are the values held by its interfaces, at any depth.
+func hashable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Func:
+		return false
+	case reflect.Interface:
+		return v.IsNil() || hashable(v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !hashable(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hashable(v.Field(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// recordKeys returns the values of the key field of the elements of the
+// slice v in order, with the index of each key. ok is false if an element
+// has no such field (or it's nil), a key can't be a map key (like a slice
+// held in an interface{} field) or keys repeat.
+func recordKeys(v reflect.Value, name string) (keys []interface{}, index map[interface{}]int, ok bool) {
+	index = make(map[interface{}]int, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		e := v.Index(i)
+		if e.Kind() == reflect.Ptr {
+			if e.IsNil() {
+				return nil, nil, false
+			}
+			e = e.Elem()
+		}
+		if e.Kind() != reflect.Struct {
+			return nil, nil, false
+		}
+		f := e.FieldByName(name)
+		if !f.IsValid() || !f.CanInterface() || !hashable(f) {
+			return nil, nil, false
+		}
+		k := f.Interface()
+		if _, dup := index[k]; dup {
+			return nil, nil, false
+		}
+		index[k] = i
+		keys = append(keys, k)
+	}
+	return keys, index, true
+}
+
+// sliceByKey compares slices of records matching the elements by the
+// SliceKeyField value. handled is false if the slices can't be keyed.
+func (c *comparer) sliceByKey(v1, v2 reflect.Value, depth int) (handled, equal bool, reason string) {
+	keys1, index1, ok := recordKeys(v1, c.opts.SliceKeyField)
+	if !ok {
+		return false, false, ""
+	}
+	keys2, index2, ok := recordKeys(v2, c.opts.SliceKeyField)
+	if !ok {
+		return false, false, ""
+	}
+	result := true
+	for _, k := range keys1 {
+		elem := PathElem{Kind: PathKey, Key: k}
+		e1 := v1.Index(index1[k])
+		if j, ok := index2[k]; ok {
+			equal, reason = c.descend(elem, e1, v2.Index(j), depth, c.deepValueEqual)
+		} else {
+			equal, reason = c.elemDiff(elem, e1, reflect.Value{}, "record only in first slice")
+		}
+		if !equal {
+			if !c.all {
+				return true, false, reason
+			}
+			result = false
+		}
+	}
+	for _, k := range keys2 {
+		if _, ok := index1[k]; ok {
+			continue
+		}
+		elem := PathElem{Kind: PathKey, Key: k}
+		if equal, reason = c.elemDiff(elem, reflect.Value{}, v2.Index(index2[k]), "record only in second slice"); !equal {
+			if !c.all {
+				return true, false, reason
+			}
+			result = false
+		}
+	}
+	return true, result, ""
+}
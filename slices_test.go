@@ -0,0 +1,629 @@
+package deepequal
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompareSlicePrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		expected   interface{}
+		actual     interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name:     "equal",
+			expected: []int{0, 1, 2},
+			actual:   []int{0, 1, 2},
+			want:     true,
+		},
+		{
+			name:     "prefix",
+			expected: []int{0, 1},
+			actual:   []int{0, 1, 2},
+			want:     true,
+		},
+		{
+			name:     "empty prefix",
+			expected: []int{},
+			actual:   []int{0},
+			want:     true,
+		},
+		{
+			name:     "array prefix of slice",
+			expected: [2]string{"a", "b"},
+			actual:   []string{"a", "b", "c"},
+			want:     true,
+		},
+		{
+			name:     "nested",
+			expected: []testStruct{{Name: "a", S: []int{1}}},
+			actual:   []testStruct{{Name: "a", S: []int{1}}, {Name: "b"}},
+			want:     true,
+		},
+		{
+			name:       "mismatch",
+			expected:   []int{0, 2},
+			actual:     []int{0, 1, 2},
+			want:       false,
+			wantReason: "[1] scalar values differ",
+		},
+		{
+			name:       "nested mismatch",
+			expected:   []testStruct{{Name: "a", S: []int{1}}},
+			actual:     []testStruct{{Name: "a", S: []int{2}}},
+			want:       false,
+			wantReason: "[0] struct.S [0] scalar values differ",
+		},
+		{
+			name:       "shorter",
+			expected:   []int{0, 1, 2},
+			actual:     []int{0, 1},
+			want:       false,
+			wantReason: "actual shorter than expected",
+		},
+		{
+			name:       "element types",
+			expected:   []int{0},
+			actual:     []int64{0},
+			want:       false,
+			wantReason: "slices have different element types",
+		},
+		{
+			name:       "not a slice",
+			expected:   []int{0},
+			actual:     0,
+			want:       false,
+			wantReason: "values are not slices or arrays",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareSlicePrefix(tt.expected, tt.actual)
+			if got != tt.want {
+				t.Errorf("CompareSlicePrefix() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareSlicePrefix() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareSliceFunc(t *testing.T) {
+	within1 := func(x, y interface{}) bool {
+		d := x.(int) - y.(int)
+		return d >= -1 && d <= 1
+	}
+	tests := []struct {
+		name       string
+		a          interface{}
+		b          interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal",
+			a:    []int{0, 1, 2},
+			b:    []int{0, 1, 2},
+			want: true,
+		},
+		{
+			name: "within 1",
+			a:    []int{0, 1, 2},
+			b:    []int{1, 0, 3},
+			want: true,
+		},
+		{
+			name: "array and slice",
+			a:    [3]int{0, 1, 2},
+			b:    []int{1, 0, 3},
+			want: true,
+		},
+		{
+			name: "empty",
+			a:    []int{},
+			b:    []int(nil),
+			want: true,
+		},
+		{
+			name:       "differ",
+			a:          []int{0, 1, 2},
+			b:          []int{0, 3, 2},
+			want:       false,
+			wantReason: "[1] elements differ",
+		},
+		{
+			name:       "lengths",
+			a:          []int{0, 1, 2},
+			b:          []int{0, 1},
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+		{
+			name:       "element types",
+			a:          []int{0},
+			b:          []string{"0"},
+			want:       false,
+			wantReason: "slices have different element types",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := CompareSliceFunc(tt.a, tt.b, within1)
+			if got != tt.want {
+				t.Errorf("CompareSliceFunc() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("CompareSliceFunc() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareWithOptions_RuneSlicesAsStrings(t *testing.T) {
+	type runesHolder struct {
+		Text  []rune
+		codes []int32
+	}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "equal",
+			a1:   []rune("héllo"),
+			a2:   []rune("héllo"),
+			opts: Options{RuneSlicesAsStrings: true},
+			want: true,
+		},
+		{
+			name:       "differ",
+			a1:         []rune("héllo"),
+			a2:         []rune("hällo"),
+			opts:       Options{RuneSlicesAsStrings: true},
+			want:       false,
+			wantReason: `rune slices differ at [1]: "héllo" != "hällo"`,
+		},
+		{
+			name:       "prefix",
+			a1:         []rune("ab"),
+			a2:         []rune("abc"),
+			opts:       Options{RuneSlicesAsStrings: true},
+			want:       false,
+			wantReason: `rune slices differ at [2]: "ab" != "abc"`,
+		},
+		{
+			name:       "nested",
+			a1:         runesHolder{Text: []rune("abc")},
+			a2:         runesHolder{Text: []rune("abd")},
+			opts:       Options{RuneSlicesAsStrings: true, SkipUnexported: true},
+			want:       false,
+			wantReason: `struct.Text rune slices differ at [2]: "abc" != "abd"`,
+		},
+		{
+			name:       "nil and empty",
+			a1:         []rune(nil),
+			a2:         []rune{},
+			opts:       Options{RuneSlicesAsStrings: true},
+			want:       false,
+			wantReason: "one slice is nil, the other is not",
+		},
+		{
+			name:       "named type",
+			a1:         testRunes("ab"),
+			a2:         testRunes("ac"),
+			opts:       Options{RuneSlicesAsStrings: true},
+			want:       false,
+			wantReason: "[1] scalar values differ",
+		},
+		{
+			name:       "disabled",
+			a1:         []rune("abc"),
+			a2:         []rune("abd"),
+			want:       false,
+			wantReason: "[2] scalar values differ",
+		},
+	})
+}
+
+type testRunes []rune
+
+func TestCompareWithOptions_Base64Bytes(t *testing.T) {
+	type blob struct {
+		Data json.RawMessage
+		raw  []byte
+	}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "equal",
+			a1:   []byte{1, 2, 3},
+			a2:   []byte{1, 2, 3},
+			opts: Options{Base64Bytes: true},
+			want: true,
+		},
+		{
+			name:       "differ",
+			a1:         []byte{1, 2, 3},
+			a2:         []byte{1, 2, 4},
+			opts:       Options{Base64Bytes: true},
+			want:       false,
+			wantReason: "bytes differ at offset 2: AQID != AQIE",
+		},
+		{
+			name:       "lengths",
+			a1:         map[string][]byte{"k": []byte("hello")},
+			a2:         map[string][]byte{"k": []byte("hello!")},
+			opts:       Options{Base64Bytes: true},
+			want:       false,
+			wantReason: "[k] bytes differ at offset 5: aGVsbG8= != aGVsbG8h",
+		},
+		{
+			name:       "named type",
+			a1:         blob{Data: json.RawMessage(`{}`)},
+			a2:         blob{Data: json.RawMessage(`[]`)},
+			opts:       Options{Base64Bytes: true, SkipUnexported: true},
+			want:       false,
+			wantReason: "struct.Data bytes differ at offset 0: e30= != W10=",
+		},
+		{
+			name:       "unexported",
+			a1:         blob{raw: []byte{0}},
+			a2:         blob{raw: []byte{1}},
+			opts:       Options{Base64Bytes: true, SkipForeignUnexported: "github.com/msaf1980/deepequal"},
+			want:       false,
+			wantReason: "struct.raw bytes differ at offset 0: AA== != AQ==",
+		},
+		{
+			name:       "disabled",
+			a1:         []byte{1, 2, 3},
+			a2:         []byte{1, 2, 4},
+			want:       false,
+			wantReason: "[2] scalar values differ",
+		},
+	})
+}
+
+type record struct {
+	ID   int
+	Name string
+}
+
+type testAnyKeyRecord struct {
+	ID   interface{}
+	Name string
+}
+
+func TestCompareWithOptions_SliceKeyFieldUnhashable(t *testing.T) {
+	opts := Options{SliceKeyField: "ID"}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "interface keys",
+			a1:   []testAnyKeyRecord{{1, "a"}, {"b", "b"}},
+			a2:   []testAnyKeyRecord{{"b", "b"}, {1, "a"}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "slice in interface key by position",
+			a1:         []testAnyKeyRecord{{[]int{1}, "a"}, {[]int{2}, "b"}},
+			a2:         []testAnyKeyRecord{{[]int{2}, "b"}, {[]int{1}, "a"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[0] struct.ID [0] scalar values differ",
+		},
+		{
+			name: "slice in interface key equal",
+			a1:   []testAnyKeyRecord{{[]int{1}, "a"}},
+			a2:   []testAnyKeyRecord{{[]int{1}, "a"}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "slice in nested key",
+			a1:         []testAnyKeyRecord{{[1]interface{}{[]int{1}}, "a"}},
+			a2:         []testAnyKeyRecord{{[1]interface{}{[]int{1}}, "b"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[0] struct.Name scalar values differ",
+		},
+	})
+}
+
+func TestCompareWithOptions_SliceKeyField(t *testing.T) {
+	opts := Options{SliceKeyField: "ID"}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "reordered",
+			a1:   []record{{1, "a"}, {2, "b"}, {3, "c"}},
+			a2:   []record{{3, "c"}, {1, "a"}, {2, "b"}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "pointers",
+			a1:   []*record{{1, "a"}, {2, "b"}},
+			a2:   []*record{{2, "b"}, {1, "a"}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "field differs",
+			a1:         []record{{1, "a"}, {2, "b"}},
+			a2:         []record{{2, "x"}, {1, "a"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[2] struct.Name scalar values differ",
+		},
+		{
+			name:       "only in first",
+			a1:         []record{{1, "a"}, {2, "b"}},
+			a2:         []record{{1, "a"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[2] record only in first slice",
+		},
+		{
+			name:       "only in second",
+			a1:         []record{{1, "a"}},
+			a2:         []record{{3, "c"}, {1, "a"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[3] record only in second slice",
+		},
+		{
+			name:       "duplicate keys",
+			a1:         []record{{1, "a"}, {1, "b"}},
+			a2:         []record{{1, "b"}, {1, "a"}},
+			opts:       opts,
+			want:       false,
+			wantReason: "[0] struct.Name scalar values differ",
+		},
+		{
+			name:       "no such field",
+			a1:         []record{{1, "a"}, {2, "b"}},
+			a2:         []record{{2, "b"}, {1, "a"}},
+			opts:       Options{SliceKeyField: "Key"},
+			want:       false,
+			wantReason: "[0] struct.ID scalar values differ",
+		},
+	})
+}
+
+func TestCompareTo_SliceKeyField(t *testing.T) {
+	var got []string
+	compareAll([]record{{1, "a"}, {2, "b"}}, []record{{3, "c"}, {1, "x"}},
+		Options{SliceKeyField: "ID"}, func(d Difference) {
+			got = append(got, d.String())
+		})
+	want := []string{
+		"[1] struct.Name scalar values differ",
+		"[2] record only in first slice",
+		"[3] record only in second slice",
+	}
+	if ok, reason := Compare(got, want); !ok {
+		t.Errorf("differences = %q, want %q: %s", got, want, reason)
+	}
+}
+
+func TestCompareWithOptions_TrimNilSliceEnds(t *testing.T) {
+	a, b := &record{1, "a"}, &record{2, "b"}
+	opts := Options{TrimNilSliceEnds: true}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "padded pointers",
+			a1:   []*record{nil, a, nil, b, nil, nil},
+			a2:   []*record{a, nil, b},
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "zero numbers",
+			a1:   []int{0, 0, 1, 2},
+			a2:   []int{1, 2, 0},
+			opts: opts,
+			want: true,
+		},
+		{
+			name: "empty strings",
+			a1:   []string{"", "x", ""},
+			a2:   []string{"x"},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "interfaces holding zero values",
+			a1:         []interface{}{nil, "x", ""},
+			a2:         []interface{}{"x", nil},
+			opts:       opts,
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+		{
+			name:       "inner zero kept",
+			a1:         []*record{nil, a, b},
+			a2:         []*record{a, nil, b},
+			opts:       opts,
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+		{
+			name:       "index in trimmed slice",
+			a1:         []int{0, 0, 1, 2},
+			a2:         []int{1, 3},
+			opts:       opts,
+			want:       false,
+			wantReason: "[1] scalar values differ",
+		},
+		{
+			name:       "all zero vs nil",
+			a1:         []int{0, 0},
+			a2:         []int(nil),
+			opts:       opts,
+			want:       false,
+			wantReason: "one slice is nil, the other is not",
+		},
+		{
+			name: "all zero vs nil ignoring nil",
+			a1:   []int{0, 0},
+			a2:   []int(nil),
+			opts: Options{TrimNilSliceEnds: true, IgnoreSliceNil: true},
+			want: true,
+		},
+		{
+			name:       "disabled",
+			a1:         []int{0, 1},
+			a2:         []int{1},
+			want:       false,
+			wantReason: "slices have different lengths",
+		},
+	})
+}
+
+func TestSliceOverlap(t *testing.T) {
+	backing := make([]int, 10)
+	tests := []struct {
+		name        string
+		a           interface{}
+		b           interface{}
+		wantOverlap bool
+		wantInfo    string
+	}{
+		{
+			name:        "subslice",
+			a:           backing[2:5:5],
+			b:           backing[3:4:4],
+			wantOverlap: true,
+			wantInfo:    "a[1:2] is b[0:1]",
+		},
+		{
+			name:        "overlapping ends",
+			a:           backing[0:4:4],
+			b:           backing[2:6:6],
+			wantOverlap: true,
+			wantInfo:    "a[2:4] is b[0:2]",
+		},
+		{
+			name:        "within capacity",
+			a:           backing[0:2],
+			b:           backing[5:7],
+			wantOverlap: true,
+			wantInfo:    "a[5:10] is b[0:5] (beyond the length)",
+		},
+		{
+			name:        "same slice",
+			a:           backing[:3:3],
+			b:           backing[:3:3],
+			wantOverlap: true,
+			wantInfo:    "a[0:3] is b[0:3]",
+		},
+		{
+			name: "adjacent",
+			a:    backing[0:2:2],
+			b:    backing[2:4],
+		},
+		{
+			name: "disjoint",
+			a:    []int{1, 2},
+			b:    []int{1, 2},
+		},
+		{
+			name: "empty",
+			a:    []int{},
+			b:    backing,
+		},
+		{
+			name:     "different types",
+			a:        []int{1},
+			b:        []int64{1},
+			wantInfo: "values are not slices of the same type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOverlap, gotInfo := SliceOverlap(tt.a, tt.b)
+			if gotOverlap != tt.wantOverlap {
+				t.Errorf("SliceOverlap() got = %v, want %v", gotOverlap, tt.wantOverlap)
+			}
+			if gotInfo != tt.wantInfo {
+				t.Errorf("SliceOverlap() got1 = %q, want %q", gotInfo, tt.wantInfo)
+			}
+		})
+	}
+}
+
+func TestCompareSliceMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        interface{}
+		b        interface{}
+		want     bool
+		wantMask []bool
+	}{
+		{
+			name:     "equal",
+			a:        []int{1, 2, 3},
+			b:        []int{1, 2, 3},
+			want:     true,
+			wantMask: []bool{false, false, false},
+		},
+		{
+			name:     "some elements differ",
+			a:        []float64{1, 2, 3, 4, 5},
+			b:        []float64{1, 0, 3, 0, 0},
+			want:     false,
+			wantMask: []bool{false, true, false, true, true},
+		},
+		{
+			name:     "longer second",
+			a:        []int{1, 5},
+			b:        []int{1, 2, 3},
+			want:     false,
+			wantMask: []bool{false, true, true},
+		},
+		{
+			name:     "longer first",
+			a:        []int{1, 2, 3},
+			b:        []int{1, 2},
+			want:     false,
+			wantMask: []bool{false, false, true},
+		},
+		{
+			name:     "nested elements",
+			a:        [2][]string{{"a"}, {"b"}},
+			b:        [2][]string{{"a"}, {"c"}},
+			want:     false,
+			wantMask: []bool{false, true},
+		},
+		{
+			name:     "nil and empty",
+			a:        []int(nil),
+			b:        []int{},
+			want:     true,
+			wantMask: []bool{},
+		},
+		{
+			name: "not slices",
+			a:    map[string]int{"a": 1},
+			b:    map[string]int{"a": 2},
+			want: false,
+		},
+		{
+			name: "different types",
+			a:    []int{1},
+			b:    []int64{1},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotMask := CompareSliceMask(tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("CompareSliceMask() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotMask, tt.wantMask) {
+				t.Errorf("CompareSliceMask() got1 = %v, want %v", gotMask, tt.wantMask)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package deepequal
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// CompareN compares a1 and a2 like Compare and returns up to n of the
+// differences found (all of them if n <= 0), empty for equal values.
+func CompareN(a1, a2 interface{}, n int) []Difference {
+	var diffs []Difference
+	compareAll(a1, a2, Options{}, func(d Difference) {
+		if n <= 0 || len(diffs) < n {
+			diffs = append(diffs, d)
+		}
+	})
+	return diffs
+}
+
+// maxTableValue is the width (in runes) values are truncated to by
+// CompareTable.
+const maxTableValue = 40
+
+// truncate shortens s to n runes, ending it with an ellipsis.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+func tableValue(v interface{}) string {
+	return truncate(fmt.Sprintf("%+v", v), maxTableValue)
+}
+
+// CompareTable renders up to n differences of a1 and a2 (see CompareN) as
+// a text table with the columns Path, Expected and Actual. Values longer
+// than 40 runes are truncated with an ellipsis. It's empty for equal
+// values.
+func CompareTable(a1, a2 interface{}, n int) string {
+	diffs := CompareN(a1, a2, n)
+	if len(diffs) == 0 {
+		return ""
+	}
+	rows := [][3]string{{"Path", "Expected", "Actual"}}
+	for _, d := range diffs {
+		path := formatPath(d.Path)
+		if path == "" {
+			path = "(root)"
+		}
+		rows = append(rows, [3]string{path, tableValue(d.A), tableValue(d.B)})
+	}
+	var widths [3]int
+	for _, row := range rows {
+		for i := range widths {
+			if w := utf8.RuneCountInString(row[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	var sb strings.Builder
+	for i, row := range rows {
+		for j := 0; j < 2; j++ {
+			sb.WriteString(row[j])
+			sb.WriteString(strings.Repeat(" ", widths[j]-utf8.RuneCountInString(row[j])))
+			sb.WriteString(" | ")
+		}
+		sb.WriteString(row[2])
+		sb.WriteByte('\n')
+		if i == 0 {
+			sb.WriteString(strings.Repeat("-", widths[0]) + "-+-" + strings.Repeat("-", widths[1]) + "-+-" + strings.Repeat("-", widths[2]) + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package deepequal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareN(t *testing.T) {
+	a1 := []int{1, 2, 3, 4}
+	a2 := []int{0, 2, 0, 0}
+	if got := CompareN(a1, a2, 2); len(got) != 2 || got[0].String() != "[0] scalar values differ" || got[1].String() != "[2] scalar values differ" {
+		t.Errorf("CompareN(2) = %v", got)
+	}
+	if got := CompareN(a1, a2, 0); len(got) != 3 {
+		t.Errorf("CompareN(0) = %v, want 3 differences", got)
+	}
+	if got := CompareN(a1, a1, 2); len(got) != 0 {
+		t.Errorf("CompareN() of equal values = %v", got)
+	}
+}
+
+func TestCompareTable(t *testing.T) {
+	tests := []struct {
+		name string
+		a1   interface{}
+		a2   interface{}
+		n    int
+		want string
+	}{
+		{
+			name: "equal",
+			a1:   testStruct{Name: "a"},
+			a2:   testStruct{Name: "a"},
+			n:    5,
+		},
+		{
+			name: "two differences",
+			a1:   testStruct{Name: "a", S: []int{1, 2}},
+			a2:   testStruct{Name: "bcd", S: []int{1, 20}},
+			n:    5,
+			want: "" +
+				"Path         | Expected | Actual\n" +
+				"-------------+----------+-------\n" +
+				"struct.Name  | a        | bcd\n" +
+				"struct.S [1] | 2        | 20\n",
+		},
+		{
+			name: "capped and truncated",
+			a1:   []string{strings.Repeat("x", 50), "a", "b"},
+			a2:   []string{"y", "b", "c"},
+			n:    2,
+			want: "" +
+				"Path | Expected                                 | Actual\n" +
+				"-----+------------------------------------------+-------\n" +
+				"[0]  | " + strings.Repeat("x", 39) + "… | y\n" +
+				"[1]  | a                                        | b\n",
+		},
+		{
+			name: "root",
+			a1:   1,
+			a2:   "1",
+			n:    1,
+			want: "" +
+				"Path   | Expected | Actual\n" +
+				"-------+----------+-------\n" +
+				"(root) | 1        | 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareTable(tt.a1, tt.a2, tt.n); got != tt.want {
+				t.Errorf("CompareTable() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package deepequal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagComparator returns the name of the comparator set for the struct
+// field with the tag `deepequal:"cmp=NAME"`, or "".
+func tagComparator(field reflect.StructField) string {
+	for _, opt := range strings.Split(field.Tag.Get("deepequal"), ",") {
+		if opt = strings.TrimSpace(opt); strings.HasPrefix(opt, "cmp=") {
+			return opt[len("cmp="):]
+		}
+	}
+	return ""
+}
+
+// namedEqual compares the field values v1 and v2 with the comparator name
+// registered in NamedComparators.
+func (c *comparer) namedEqual(name string) func(v1, v2 reflect.Value, depth int) (bool, string) {
+	return func(v1, v2 reflect.Value, depth int) (bool, string) {
+		cmp, ok := c.opts.NamedComparators[name]
+		if !ok {
+			return false, fmt.Sprintf("unknown comparator %q", name)
+		}
+		if cmp(v1.Interface(), v2.Interface()) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("values differ by comparator %q", name)
+	}
+}
@@ -0,0 +1,69 @@
+package deepequal
+
+import (
+	"strings"
+	"testing"
+)
+
+type testAccount struct {
+	Email string `deepequal:"cmp=ci"`
+	Name  string
+	Roles []string `json:"roles" deepequal:"x, cmp=ci"`
+}
+
+type testNote struct {
+	Text string `deepequal:"cmp=missing"`
+}
+
+func TestCompareWithOptions_NamedComparators(t *testing.T) {
+	ci := func(a, b interface{}) bool {
+		switch a := a.(type) {
+		case string:
+			return strings.EqualFold(a, b.(string))
+		case []string:
+			return strings.EqualFold(strings.Join(a, ","), strings.Join(b.([]string), ","))
+		}
+		return false
+	}
+	opts := Options{NamedComparators: map[string]func(a, b interface{}) bool{"ci": ci}}
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "case insensitive",
+			a1:   testAccount{Email: "A@example.com", Name: "a", Roles: []string{"Admin"}},
+			a2:   testAccount{Email: "a@EXAMPLE.com", Name: "a", Roles: []string{"admin"}},
+			opts: opts,
+			want: true,
+		},
+		{
+			name:       "differ",
+			a1:         []testAccount{{Email: "a@example.com"}},
+			a2:         []testAccount{{Email: "b@example.com"}},
+			opts:       opts,
+			want:       false,
+			wantReason: `[0] struct.Email values differ by comparator "ci"`,
+		},
+		{
+			name:       "untagged field",
+			a1:         testAccount{Email: "a@example.com", Name: "a"},
+			a2:         testAccount{Email: "a@example.com", Name: "A"},
+			opts:       opts,
+			want:       false,
+			wantReason: "struct.Name scalar values differ",
+		},
+		{
+			name:       "unknown comparator",
+			a1:         testNote{Text: "x"},
+			a2:         testNote{Text: "x"},
+			opts:       opts,
+			want:       false,
+			wantReason: `struct.Text unknown comparator "missing"`,
+		},
+		{
+			name:       "disabled",
+			a1:         testAccount{Email: "A@example.com"},
+			a2:         testAccount{Email: "a@example.com"},
+			want:       false,
+			wantReason: "struct.Email scalar values differ",
+		},
+	})
+}
@@ -0,0 +1,96 @@
+package deepequal
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"reflect"
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateSources returns the sources of the templates associated with a
+// template (defined ones only) by name.
+func templateSources(trees map[string]*parse.Tree) ([]string, map[string]string) {
+	names := make([]string, 0, len(trees))
+	sources := make(map[string]string, len(trees))
+	for name, tree := range trees {
+		if tree == nil || tree.Root == nil {
+			continue
+		}
+		names = append(names, name)
+		sources[name] = tree.Root.String()
+	}
+	sort.Strings(names)
+	return names, sources
+}
+
+func equalStrings(s1, s2 []string) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+	for i := range s1 {
+		if s1[i] != s2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compareTemplateTrees compares templates by their names and the sources
+// of the associated templates, as parsed.
+func compareTemplateTrees(name1, name2 string, trees1, trees2 map[string]*parse.Tree) (bool, string) {
+	if name1 != name2 {
+		return false, fmt.Sprintf("template names differ: %q != %q", name1, name2)
+	}
+	names1, sources1 := templateSources(trees1)
+	names2, sources2 := templateSources(trees2)
+	if !equalStrings(names1, names2) {
+		return false, fmt.Sprintf("defined templates differ: %q != %q", names1, names2)
+	}
+	for _, name := range names1 {
+		if sources1[name] != sources2[name] {
+			return false, fmt.Sprintf("template %q differs: %q != %q", name, sources1[name], sources2[name])
+		}
+	}
+	return true, ""
+}
+
+func textTemplateTrees(t *template.Template) map[string]*parse.Tree {
+	trees := make(map[string]*parse.Tree)
+	for _, tmpl := range t.Templates() {
+		trees[tmpl.Name()] = tmpl.Tree
+	}
+	return trees
+}
+
+func htmlTemplateTrees(t *htmltemplate.Template) map[string]*parse.Tree {
+	trees := make(map[string]*parse.Tree)
+	for _, tmpl := range t.Templates() {
+		trees[tmpl.Name()] = tmpl.Tree
+	}
+	return trees
+}
+
+// compareTextTemplate compares *text/template.Template values by name and
+// the sources of their defined templates.
+func compareTextTemplate(c *comparer, v1, v2 reflect.Value, depth int) (bool, string) {
+	t1 := v1.Interface().(*template.Template)
+	t2 := v2.Interface().(*template.Template)
+	if t1 == nil || t2 == nil {
+		return bothOrNone(t1 == nil, t2 == nil, "one pointer is nil, the other is not")
+	}
+	return compareTemplateTrees(t1.Name(), t2.Name(), textTemplateTrees(t1), textTemplateTrees(t2))
+}
+
+// compareHTMLTemplate compares *html/template.Template values like
+// compareTextTemplate. Escaping of executed templates changes their trees,
+// so only templates which are executed the same way compare equal.
+func compareHTMLTemplate(c *comparer, v1, v2 reflect.Value, depth int) (bool, string) {
+	t1 := v1.Interface().(*htmltemplate.Template)
+	t2 := v2.Interface().(*htmltemplate.Template)
+	if t1 == nil || t2 == nil {
+		return bothOrNone(t1 == nil, t2 == nil, "one pointer is nil, the other is not")
+	}
+	return compareTemplateTrees(t1.Name(), t2.Name(), htmlTemplateTrees(t1), htmlTemplateTrees(t2))
+}
@@ -0,0 +1,87 @@
+package deepequal
+
+import (
+	htmltemplate "html/template"
+	"testing"
+	"text/template"
+)
+
+type testTemplates struct {
+	Text *template.Template
+	HTML *htmltemplate.Template
+}
+
+func TestCompare_Templates(t *testing.T) {
+	text := func(name, src string) *template.Template {
+		return template.Must(template.New(name).Parse(src))
+	}
+	html := func(name, src string) *htmltemplate.Template {
+		return htmltemplate.Must(htmltemplate.New(name).Parse(src))
+	}
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "identical",
+			a1:   text("page", `Hello, {{.Name}}!{{define "footer"}}bye{{end}}`),
+			a2:   text("page", `Hello, {{ .Name }}!{{define "footer"}}bye{{end}}`),
+			want: true,
+		},
+		{
+			name:       "body differ",
+			a1:         testTemplates{Text: text("page", `Hello, {{.Name}}!`)},
+			a2:         testTemplates{Text: text("page", `Hi, {{.Name}}!`)},
+			want:       false,
+			wantReason: `struct.Text template "page" differs: "Hello, {{.Name}}!" != "Hi, {{.Name}}!"`,
+		},
+		{
+			name:       "names differ",
+			a1:         text("a", `x`),
+			a2:         text("b", `x`),
+			want:       false,
+			wantReason: `template names differ: "a" != "b"`,
+		},
+		{
+			name:       "defined templates differ",
+			a1:         text("page", `{{define "header"}}h{{end}}x`),
+			a2:         text("page", `{{define "footer"}}h{{end}}x`),
+			want:       false,
+			wantReason: `defined templates differ: ["header" "page"] != ["footer" "page"]`,
+		},
+		{
+			name:       "nil",
+			a1:         testTemplates{Text: text("a", `x`)},
+			a2:         testTemplates{},
+			want:       false,
+			wantReason: "struct.Text one pointer is nil, the other is not",
+		},
+		{
+			name: "html identical",
+			a1:   testTemplates{HTML: html("page", `<p>{{.}}</p>`)},
+			a2:   testTemplates{HTML: html("page", `<p>{{.}}</p>`)},
+			want: true,
+		},
+		{
+			name:       "html differ",
+			a1:         html("page", `<p>{{.}}</p>`),
+			a2:         html("page", `<div>{{.}}</div>`),
+			want:       false,
+			wantReason: `template "page" differs: "<p>{{.}}</p>" != "<div>{{.}}</div>"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package deepequal
+
+import (
+	"reflect"
+	"sort"
+)
+
+// isPathPrefix tells if the path p is a prefix of the path q.
+func isPathPrefix(p, q []PathElem) bool {
+	if len(p) > len(q) {
+		return false
+	}
+	for i := range p {
+		if !samePathElem(p[i], q[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare3Way compares the values a and b derived from base, like for a
+// merge, and returns the conflicts: paths where both a and b changed base
+// in different ways, like 'struct.Name conflicting changes', sorted.
+// Changes made only by one of them, or the same by both, don't conflict.
+// Changes are found like with Compare, so a slice or map whose length
+// changed counts as changed as a whole.
+func Compare3Way(base, a, b interface{}) (conflicts []string) {
+	var changedA, changedB [][]PathElem
+	compareAll(base, a, Options{}, func(d Difference) { changedA = append(changedA, d.Path) })
+	compareAll(base, b, Options{}, func(d Difference) { changedB = append(changedB, d.Path) })
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	c := newComparer(&Options{})
+	seen := make(map[string]bool)
+	for _, pa := range changedA {
+		for _, pb := range changedB {
+			var path []PathElem
+			switch {
+			case isPathPrefix(pa, pb):
+				path = pa
+			case isPathPrefix(pb, pa):
+				path = pb
+			default:
+				continue
+			}
+			conflict := Difference{Path: path, Reason: "conflicting changes"}.String()
+			if seen[conflict] {
+				continue
+			}
+			seen[conflict] = true
+			ea, okA := follow(va, path)
+			eb, okB := follow(vb, path)
+			if okA && okB {
+				if equal, _ := c.probe().deepValueEqual(ea, eb, 0); equal {
+					continue
+				}
+			} else if okA == okB {
+				continue
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
@@ -0,0 +1,81 @@
+package deepequal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare3Way(t *testing.T) {
+	base := testInventory{
+		Name:  "a",
+		Items: []testItem{{ID: 1}, {ID: 2}},
+		Stock: map[string]int{"x": 1, "y": 2},
+	}
+	with := func(f func(inv *testInventory)) testInventory {
+		inv := base
+		inv.Items = append([]testItem(nil), base.Items...)
+		inv.Stock = map[string]int{}
+		for k, v := range base.Stock {
+			inv.Stock[k] = v
+		}
+		f(&inv)
+		return inv
+	}
+	tests := []struct {
+		name string
+		base interface{}
+		a    interface{}
+		b    interface{}
+		want []string
+	}{
+		{
+			name: "non-overlapping",
+			base: base,
+			a:    with(func(inv *testInventory) { inv.Name = "b" }),
+			b:    with(func(inv *testInventory) { inv.Items[1].ID = 3; inv.Stock["x"] = 5 }),
+		},
+		{
+			name: "same change",
+			base: base,
+			a:    with(func(inv *testInventory) { inv.Name = "b"; inv.Stock["y"] = 0 }),
+			b:    with(func(inv *testInventory) { inv.Name = "b" }),
+		},
+		{
+			name: "conflicts",
+			base: base,
+			a:    with(func(inv *testInventory) { inv.Name = "b"; inv.Items[0].ID = 5; inv.Stock["x"] = 7 }),
+			b:    with(func(inv *testInventory) { inv.Name = "c"; inv.Items[0].ID = 6; inv.Stock["x"] = 7 }),
+			want: []string{
+				"struct.Items [0] struct.ID conflicting changes",
+				"struct.Name conflicting changes",
+			},
+		},
+		{
+			name: "nested in a changed value",
+			base: base,
+			a:    with(func(inv *testInventory) { inv.Items[1].Tags = []string{"t"} }),
+			b:    with(func(inv *testInventory) { inv.Items = inv.Items[:1] }),
+			want: []string{"struct.Items conflicting changes"},
+		},
+		{
+			name: "one side only",
+			base: base,
+			a:    base,
+			b:    with(func(inv *testInventory) { inv.Name = "c"; inv.Items = nil }),
+		},
+		{
+			name: "top level",
+			base: 0,
+			a:    1,
+			b:    2,
+			want: []string{"conflicting changes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare3Way(tt.base, tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare3Way() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
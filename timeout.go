@@ -0,0 +1,36 @@
+package deepequal
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const timedOut = "comparison timed out"
+
+// compareTimeout compares a1 and a2 like compareRoot in a goroutine, giving
+// up after the Timeout. The abandoned comparison stops at the next value
+// it compares, a comparator function called meanwhile still runs to its
+// end.
+func (c *comparer) compareTimeout(a1, a2 interface{}) (bool, string) {
+	type result struct {
+		equal  bool
+		reason string
+	}
+	var stop int32
+	c.stop = &stop
+	done := make(chan result, 1)
+	go func() {
+		equal, reason := c.compareRoot(a1, a2)
+		done <- result{equal, reason}
+	}()
+
+	timer := time.NewTimer(c.opts.Timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.equal, r.reason
+	case <-timer.C:
+		atomic.StoreInt32(&stop, 1)
+		return false, timedOut
+	}
+}
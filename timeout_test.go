@@ -0,0 +1,50 @@
+package deepequal
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testSlow struct {
+	Values []string `deepequal:"cmp=slow"`
+}
+
+func TestCompareWithOptions_Timeout(t *testing.T) {
+	var calls int32
+	slow := func(a, b interface{}) bool {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return true
+	}
+	opts := Options{
+		NamedComparators: map[string]func(a, b interface{}) bool{"slow": slow},
+		Timeout:          30 * time.Millisecond,
+	}
+	items, other := make([]testSlow, 100), make([]testSlow, 100)
+
+	start := time.Now()
+	equal, reason := CompareWithOptions(items, other, opts)
+	if equal || reason != "comparison timed out" {
+		t.Errorf("CompareWithOptions() = %v, %q, want a timeout", equal, reason)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CompareWithOptions() took %v", elapsed)
+	}
+
+	// the abandoned comparison stops after the comparator call in progress
+	time.Sleep(100 * time.Millisecond)
+	n := atomic.LoadInt32(&calls)
+	time.Sleep(100 * time.Millisecond)
+	if after := atomic.LoadInt32(&calls); after != n || n >= int32(len(items)) {
+		t.Errorf("comparator calls = %d, then %d, want the comparison stopped", n, after)
+	}
+
+	opts.Timeout = time.Minute
+	if equal, reason := CompareWithOptions([]testSlow{{}}, []testSlow{{}}, opts); !equal {
+		t.Errorf("CompareWithOptions() = %v, %q, want true", equal, reason)
+	}
+	if equal, reason := CompareWithOptions([]int{1}, []int{2}, Options{Timeout: time.Minute}); equal || reason != "[0] scalar values differ" {
+		t.Errorf("CompareWithOptions() = %v, %q", equal, reason)
+	}
+}
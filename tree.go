@@ -0,0 +1,40 @@
+package deepequal
+
+// DiffNode is a node of the tree of compared values built by CompareTree.
+type DiffNode struct {
+	// Name is the path step to the node, like 'struct.NAME', '[INDEX]' or
+	// '[KEY]'. It's empty for the root.
+	Name  string
+	Equal bool
+	// Reason describes the difference found at the node itself.
+	Reason string
+	// A and B are the compared values of a differing node which has no
+	// children.
+	A, B     interface{}
+	Children []*DiffNode
+}
+
+// Prune removes fully equal subtrees below n and returns n.
+func (n *DiffNode) Prune() *DiffNode {
+	children := n.Children[:0]
+	for _, child := range n.Children {
+		if !child.Equal {
+			children = append(children, child.Prune())
+		}
+	}
+	n.Children = children
+	return n
+}
+
+// CompareTree compares a1 and a2 like Compare, but returns a tree mirroring
+// the compared struct fields, slice and array elements and map values,
+// with every difference found rather than only the first. Use Prune to
+// leave only the differing subtrees.
+func CompareTree(a1, a2 interface{}) *DiffNode {
+	root := &DiffNode{}
+	c := newComparer(&Options{})
+	c.all = true
+	c.node = root
+	root.Equal, _ = c.compareRoot(a1, a2)
+	return root
+}
@@ -0,0 +1,110 @@
+package deepequal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// dumpTree formats the tree as indented lines.
+func dumpTree(n *DiffNode) string {
+	var sb strings.Builder
+	var dump func(n *DiffNode, indent string)
+	dump = func(n *DiffNode, indent string) {
+		fmt.Fprintf(&sb, "%s%s %v", indent, n.Name, n.Equal)
+		if n.Reason != "" {
+			fmt.Fprintf(&sb, " %s", n.Reason)
+		}
+		if len(n.Children) == 0 && !n.Equal {
+			fmt.Fprintf(&sb, " %v %v", n.A, n.B)
+		}
+		sb.WriteString("\n")
+		for _, child := range n.Children {
+			dump(child, indent+"  ")
+		}
+	}
+	dump(n, "")
+	return sb.String()
+}
+
+func TestCompareTree(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       string
+		wantPruned string
+	}{
+		{
+			name: "equal",
+			a1:   testStruct{Name: "a", S: []int{1}},
+			a2:   testStruct{Name: "a", S: []int{1}},
+			want: " true\n" +
+				"  struct.Name true\n" +
+				"  struct.S true\n" +
+				"    [0] true\n" +
+				"  struct.M true\n",
+			wantPruned: " true\n",
+		},
+		{
+			name: "differ",
+			a1:   testStruct{Name: "a", S: []int{1, 2, 3}, M: map[int]string{1: "1"}},
+			a2:   testStruct{Name: "b", S: []int{1, 5, 6}, M: map[int]string{1: "1"}},
+			want: " false\n" +
+				"  struct.Name false scalar values differ a b\n" +
+				"  struct.S false\n" +
+				"    [0] true\n" +
+				"    [1] false scalar values differ 2 5\n" +
+				"    [2] false scalar values differ 3 6\n" +
+				"  struct.M true\n" +
+				"    [1] true\n",
+			wantPruned: " false\n" +
+				"  struct.Name false scalar values differ a b\n" +
+				"  struct.S false\n" +
+				"    [1] false scalar values differ 2 5\n" +
+				"    [2] false scalar values differ 3 6\n",
+		},
+		{
+			name: "lengths",
+			a1:   testStruct{Name: "a", S: []int{1, 2, 3}},
+			a2:   testStruct{Name: "a", S: []int{1}},
+			want: " false\n" +
+				"  struct.Name true\n" +
+				"  struct.S false slices have different lengths [1 2 3] [1]\n" +
+				"  struct.M true\n",
+			wantPruned: " false\n" +
+				"  struct.S false slices have different lengths [1 2 3] [1]\n",
+		},
+		{
+			name: "unexported",
+			a1:   testStructS{_name: "a", Name: "a"},
+			a2:   testStructS{_name: "a", Name: "b"},
+			want: " false\n" +
+				"  struct._name false unexported a a\n" +
+				"  struct.Name false scalar values differ a b\n" +
+				"  struct.S true\n" +
+				"  struct.M true\n",
+			wantPruned: " false\n" +
+				"  struct._name false unexported a a\n" +
+				"  struct.Name false scalar values differ a b\n",
+		},
+		{
+			name:       "scalar",
+			a1:         1,
+			a2:         2,
+			want:       " false scalar values differ 1 2\n",
+			wantPruned: " false scalar values differ 1 2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := CompareTree(tt.a1, tt.a2)
+			if got := dumpTree(tree); got != tt.want {
+				t.Errorf("CompareTree() got\n%s\nwant\n%s", got, tt.want)
+			}
+			if got := dumpTree(tree.Prune()); got != tt.wantPruned {
+				t.Errorf("CompareTree().Prune() got\n%s\nwant\n%s", got, tt.wantPruned)
+			}
+		})
+	}
+}
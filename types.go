@@ -0,0 +1,161 @@
+package deepequal
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// typeComparer compares two values of a type with unexported state through
+// its public API. Both values are of the same type and can be converted
+// with Interface.
+type typeComparer func(c *comparer, v1, v2 reflect.Value, depth int) (bool, string)
+
+// typeComparers holds comparers for standard library types which can't be
+// compared field by field.
+var typeComparers = map[reflect.Type]typeComparer{
+	reflect.TypeOf(bytes.Buffer{}):    compareBuffer,
+	reflect.TypeOf(strings.Builder{}): compareBuilder,
+	reflect.TypeOf(time.Location{}):   compareLocation,
+	reflect.TypeOf(&time.Location{}):  compareLocation,
+
+	reflect.TypeOf((*template.Template)(nil)):     compareTextTemplate,
+	reflect.TypeOf((*htmltemplate.Template)(nil)): compareHTMLTemplate,
+}
+
+// compareType compares v1 and v2 with the registered comparer for their
+// type. handled is false if there is none.
+func (c *comparer) compareType(v1, v2 reflect.Value, depth int) (handled, equal bool, reason string) {
+	if !v1.CanInterface() || !v2.CanInterface() {
+		return false, false, ""
+	}
+	cmp, ok := typeComparers[v1.Type()]
+	if c.opts.IgnoreMonotonicClock && v1.Type() == timeType {
+		cmp, ok = compareTime, true
+	}
+	if !ok {
+		if cmp = atomicComparer(v1.Type()); cmp == nil {
+			return false, false, ""
+		}
+	}
+	equal, reason = cmp(c, v1, v2, depth)
+	return true, equal, reason
+}
+
+func compareBuffer(c *comparer, v1, v2 reflect.Value, depth int) (bool, string) {
+	b1 := v1.Interface().(bytes.Buffer)
+	b2 := v2.Interface().(bytes.Buffer)
+	if bytes.Equal(b1.Bytes(), b2.Bytes()) {
+		return true, ""
+	}
+	return false, "bytes.Buffer contents differ"
+}
+
+func compareBuilder(c *comparer, v1, v2 reflect.Value, depth int) (bool, string) {
+	// String doesn't check for copying, unlike the write methods
+	b1 := v1.Interface().(strings.Builder)
+	b2 := v2.Interface().(strings.Builder)
+	if b1.String() == b2.String() {
+		return true, ""
+	}
+	return false, "strings.Builder contents differ"
+}
+
+// compareLocation compares time.Location (or *time.Location) values by
+// name. A nil *time.Location is UTC.
+func compareLocation(c *comparer, v1, v2 reflect.Value, depth int) (bool, string) {
+	var l1, l2 *time.Location
+	if v1.Kind() == reflect.Ptr {
+		l1 = v1.Interface().(*time.Location)
+		l2 = v2.Interface().(*time.Location)
+	} else {
+		loc1 := v1.Interface().(time.Location)
+		loc2 := v2.Interface().(time.Location)
+		l1, l2 = &loc1, &loc2
+	}
+	if l1.String() == l2.String() {
+		return true, ""
+	}
+	return false, fmt.Sprintf("locations differ: %q != %q", l1, l2)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareTime compares time.Time values with ==, with the monotonic clock
+// readings stripped by Round(0), so the location still matters.
+func compareTime(c *comparer, v1, v2 reflect.Value, depth int) (bool, string) {
+	t1 := v1.Interface().(time.Time).Round(0)
+	t2 := v2.Interface().(time.Time).Round(0)
+	if t1 == t2 {
+		return true, ""
+	}
+	return false, fmt.Sprintf("times differ: %s != %s", t1, t2)
+}
+
+// atomicComparer returns a comparer for the sync/atomic types (Value, Int64,
+// Pointer[T] and so on) which compares the loaded values, or nil if t isn't
+// one of them.
+func atomicComparer(t reflect.Type) typeComparer {
+	if t.Kind() != reflect.Struct || t.PkgPath() != "sync/atomic" {
+		return nil
+	}
+	m, ok := reflect.PtrTo(t).MethodByName("Load")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+		return nil
+	}
+	return func(c *comparer, v1, v2 reflect.Value, depth int) (bool, string) {
+		// a copy gives the same snapshot as loading from the original
+		p1, _ := addressable(v1)
+		p2, _ := addressable(v2)
+		l1 := m.Func.Call([]reflect.Value{p1.Addr()})[0]
+		l2 := m.Func.Call([]reflect.Value{p2.Addr()})[0]
+		return c.deepValueEqual(l1, l2, depth+1)
+	}
+}
+
+// syncTypes are skipped with IgnoreSyncPrimitives.
+var syncTypes = map[reflect.Type]bool{
+	reflect.TypeOf(sync.Mutex{}):      true,
+	reflect.TypeOf(&sync.Mutex{}):     true,
+	reflect.TypeOf(sync.RWMutex{}):    true,
+	reflect.TypeOf(&sync.RWMutex{}):   true,
+	reflect.TypeOf(sync.WaitGroup{}):  true,
+	reflect.TypeOf(&sync.WaitGroup{}): true,
+	reflect.TypeOf(sync.Once{}):       true,
+	reflect.TypeOf(&sync.Once{}):      true,
+}
+
+// ignored tells if values of the type t are always treated as equal.
+func (c *comparer) ignored(t reflect.Type) bool {
+	return c.opts.IgnoreTypes[t] || (c.opts.IgnoreSyncPrimitives && syncTypes[t])
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// stringBytesPair tells if one of t1 and t2 is a string type and the other
+// one a byte slice type, for StringBytesInterchangeable.
+func stringBytesPair(t1, t2 reflect.Type) bool {
+	return t1.Kind() == reflect.String && isBytes(t2) || isBytes(t1) && t2.Kind() == reflect.String
+}
+
+func stringOrBytes(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return string(v.Bytes())
+}
+
+// compareStringBytes compares a string and a byte slice by their contents.
+func compareStringBytes(v1, v2 reflect.Value) (bool, string) {
+	if stringOrBytes(v1) != stringOrBytes(v2) {
+		return false, "string and bytes contents differ"
+	}
+	return true, ""
+}
@@ -0,0 +1,72 @@
+//go:build go1.19
+
+package deepequal
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+type testAtomics struct {
+	Count atomic.Int64
+	Ready atomic.Bool
+	Last  atomic.Pointer[testStruct]
+}
+
+func newTestAtomics(count int64, name string) *testAtomics {
+	a := &testAtomics{}
+	a.Count.Store(count)
+	a.Ready.Store(true)
+	if name != "" {
+		a.Last.Store(&testStruct{Name: name})
+	}
+	return a
+}
+
+func TestCompare_Atomics(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal",
+			a1:   newTestAtomics(5, "a"),
+			a2:   newTestAtomics(5, "a"),
+			want: true,
+		},
+		{
+			name: "equal by value",
+			a1:   *newTestAtomics(5, "a"),
+			a2:   *newTestAtomics(5, "a"),
+			want: true,
+		},
+		{
+			name:       "atomic.Int64 differ",
+			a1:         newTestAtomics(5, "a"),
+			a2:         newTestAtomics(6, "a"),
+			want:       false,
+			wantReason: "struct.Count scalar values differ",
+		},
+		{
+			name:       "atomic.Pointer differ",
+			a1:         newTestAtomics(5, "a"),
+			a2:         newTestAtomics(5, "b"),
+			want:       false,
+			wantReason: "struct.Last struct.Name scalar values differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package deepequal
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testBuffers struct {
+	Name string
+	Buf  bytes.Buffer
+	Sb   *strings.Builder
+}
+
+func newTestBuffers(buf, sb string) testBuffers {
+	b := testBuffers{Name: "b", Sb: &strings.Builder{}}
+	b.Buf.WriteString(buf)
+	b.Sb.WriteString(sb)
+	return b
+}
+
+func TestCompare_Buffers(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal",
+			a1:   newTestBuffers("abc", "def"),
+			a2:   newTestBuffers("abc", "def"),
+			want: true,
+		},
+		{
+			name: "empty",
+			a1:   testBuffers{Sb: &strings.Builder{}},
+			a2:   testBuffers{Sb: &strings.Builder{}},
+			want: true,
+		},
+		{
+			name:       "bytes.Buffer differ",
+			a1:         newTestBuffers("abc", "def"),
+			a2:         newTestBuffers("abd", "def"),
+			want:       false,
+			wantReason: "struct.Buf bytes.Buffer contents differ",
+		},
+		{
+			name:       "strings.Builder differ",
+			a1:         newTestBuffers("abc", "def"),
+			a2:         newTestBuffers("abc", "de"),
+			want:       false,
+			wantReason: "struct.Sb strings.Builder contents differ",
+		},
+		{
+			name: "bytes.Buffer pointers",
+			a1:   bytes.NewBufferString("abc"),
+			a2:   bytes.NewBufferString("abc"),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompare_BufferReadOffset(t *testing.T) {
+	// only unread content counts
+	b1 := bytes.NewBufferString("xabc")
+	b1.ReadByte()
+	b2 := bytes.NewBufferString("abc")
+	if equal, reason := Compare(b1, b2); !equal {
+		t.Errorf("Compare() got = %v, want true (%s)", equal, reason)
+	}
+}
+
+type testAtomicValue struct {
+	Name string
+	V    atomic.Value
+}
+
+func newTestAtomicValue(v interface{}) *testAtomicValue {
+	a := &testAtomicValue{Name: "a"}
+	if v != nil {
+		a.V.Store(v)
+	}
+	return a
+}
+
+func TestCompare_AtomicValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "equal",
+			a1:   newTestAtomicValue([]int{1, 2}),
+			a2:   newTestAtomicValue([]int{1, 2}),
+			want: true,
+		},
+		{
+			name: "never stored",
+			a1:   newTestAtomicValue(nil),
+			a2:   newTestAtomicValue(nil),
+			want: true,
+		},
+		{
+			name:       "one never stored",
+			a1:         newTestAtomicValue(nil),
+			a2:         newTestAtomicValue(1),
+			want:       false,
+			wantReason: "struct.V both interfaces must be nil",
+		},
+		{
+			name:       "differ",
+			a1:         newTestAtomicValue([]int{1, 2}),
+			a2:         newTestAtomicValue([]int{1, 3}),
+			want:       false,
+			wantReason: "struct.V [1] scalar values differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+type testLocations struct {
+	Loc  *time.Location
+	Locs []*time.Location
+}
+
+func TestCompare_Location(t *testing.T) {
+	utc, err := time.LoadLocation("UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		a1         interface{}
+		a2         interface{}
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "loaded UTC",
+			a1:   time.UTC,
+			a2:   utc,
+			want: true,
+		},
+		{
+			name: "fixed zone UTC",
+			a1:   time.UTC,
+			a2:   time.FixedZone("UTC", 0),
+			want: true,
+		},
+		{
+			name: "nil is UTC",
+			a1:   testLocations{Loc: nil, Locs: []*time.Location{time.UTC}},
+			a2:   testLocations{Loc: utc, Locs: []*time.Location{nil}},
+			want: true,
+		},
+		{
+			name: "values",
+			a1:   *time.FixedZone("MSK", 3*3600),
+			a2:   *time.FixedZone("MSK", 3*3600),
+			want: true,
+		},
+		{
+			name:       "differ",
+			a1:         testLocations{Loc: time.UTC},
+			a2:         testLocations{Loc: time.FixedZone("MSK", 3*3600)},
+			want:       false,
+			wantReason: `struct.Loc locations differ: "UTC" != "MSK"`,
+		},
+		{
+			name:       "nil differ",
+			a1:         testLocations{Locs: []*time.Location{nil}},
+			a2:         testLocations{Locs: []*time.Location{time.FixedZone("MSK", 3*3600)}},
+			want:       false,
+			wantReason: `struct.Locs [0] locations differ: "UTC" != "MSK"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReason := Compare(tt.a1, tt.a2)
+			if got != tt.want {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("Compare() got1 = '%v', want '%v'", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCompareWithOptions_IgnoreMonotonicClock(t *testing.T) {
+	now := time.Now()
+	at := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	runOptionsTests(t, []optionsTest{
+		{
+			name: "monotonic and stripped",
+			a1:   now,
+			a2:   now.Round(0),
+			opts: Options{IgnoreMonotonicClock: true},
+			want: true,
+		},
+		{
+			name: "nested",
+			a1:   testEvent{Name: "a", At: now},
+			a2:   testEvent{Name: "a", At: now.Round(0)},
+			opts: Options{IgnoreMonotonicClock: true},
+			want: true,
+		},
+		{
+			name:       "differ",
+			a1:         testEvent{Name: "a", At: at},
+			a2:         testEvent{Name: "a", At: at.Add(time.Second)},
+			opts:       Options{IgnoreMonotonicClock: true},
+			want:       false,
+			wantReason: "struct.At times differ: 2022-05-01 10:00:00 +0000 UTC != 2022-05-01 10:00:01 +0000 UTC",
+		},
+		{
+			name:       "locations differ",
+			a1:         at,
+			a2:         at.In(time.FixedZone("MSK", 3*3600)),
+			opts:       Options{IgnoreMonotonicClock: true},
+			want:       false,
+			wantReason: "times differ: 2022-05-01 10:00:00 +0000 UTC != 2022-05-01 13:00:00 +0300 MSK",
+		},
+		{
+			name:       "disabled",
+			a1:         now,
+			a2:         now.Round(0),
+			want:       false,
+			wantReason: "struct.wall unexported",
+		},
+	})
+}